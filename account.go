@@ -1,6 +1,7 @@
 package goanda
 
 import (
+	"context"
 	"time"
 )
 
@@ -12,267 +13,228 @@ type AccountProperties struct {
 	Tags         []string
 }
 
+// Account is the full state of an account, with its pending orders, open trades and positions
+type Account struct {
+	NAV                         Decimal     `json:"NAV"`
+	Alias                       string      `json:"alias"`
+	Balance                     Decimal     `json:"balance"`
+	CreatedByUserID             int         `json:"createdByUserID"`
+	CreatedTime                 time.Time   `json:"createdTime"`
+	Currency                    string      `json:"currency"`
+	HedgingEnabled              bool        `json:"hedgingEnabled"`
+	ID                          string      `json:"id"`
+	LastTransactionID           string      `json:"lastTransactionID"`
+	MarginAvailable             Decimal     `json:"marginAvailable"`
+	MarginCloseoutMarginUsed    Decimal     `json:"marginCloseoutMarginUsed"`
+	MarginCloseoutNAV           Decimal     `json:"marginCloseoutNAV"`
+	MarginCloseoutPercent       Decimal     `json:"marginCloseoutPercent"`
+	MarginCloseoutPositionValue Decimal     `json:"marginCloseoutPositionValue"`
+	MarginCloseoutUnrealizedPL  Decimal     `json:"marginCloseoutUnrealizedPL"`
+	MarginRate                  Decimal     `json:"marginRate"`
+	MarginUsed                  Decimal     `json:"marginUsed"`
+	OpenPositionCount           int         `json:"openPositionCount"`
+	OpenTradeCount              int         `json:"openTradeCount"`
+	Orders                      []OrderInfo `json:"orders"`
+	PendingOrderCount           int         `json:"pendingOrderCount"`
+	Pl                          Decimal     `json:"pl"`
+	PositionValue               Decimal     `json:"positionValue"`
+	Positions                   []Position  `json:"positions"`
+	ResettablePL                Decimal     `json:"resettablePL"`
+	Trades                      []Trade     `json:"trades"`
+	UnrealizedPL                Decimal     `json:"unrealizedPL"`
+	WithdrawalLimit             Decimal     `json:"withdrawalLimit"`
+}
+
 type AccountInfo struct {
-	Account struct {
-		NAV                         string        `json:"NAV"`
-		Alias                       string        `json:"alias"`
-		Balance                     string        `json:"balance"`
-		CreatedByUserID             int           `json:"createdByUserID"`
-		CreatedTime                 time.Time     `json:"createdTime"`
-		Currency                    string        `json:"currency"`
-		HedgingEnabled              bool          `json:"hedgingEnabled"`
-		ID                          string        `json:"id"`
-		LastTransactionID           string        `json:"lastTransactionID"`
-		MarginAvailable             string        `json:"marginAvailable"`
-		MarginCloseoutMarginUsed    string        `json:"marginCloseoutMarginUsed"`
-		MarginCloseoutNAV           string        `json:"marginCloseoutNAV"`
-		MarginCloseoutPercent       string        `json:"marginCloseoutPercent"`
-		MarginCloseoutPositionValue string        `json:"marginCloseoutPositionValue"`
-		MarginCloseoutUnrealizedPL  string        `json:"marginCloseoutUnrealizedPL"`
-		MarginRate                  string        `json:"marginRate"`
-		MarginUsed                  string        `json:"marginUsed"`
-		OpenPositionCount           int           `json:"openPositionCount"`
-		OpenTradeCount              int           `json:"openTradeCount"`
-		Orders                      []interface{} `json:"orders"`
-		PendingOrderCount           int           `json:"pendingOrderCount"`
-		Pl                          string        `json:"pl"`
-		PositionValue               string        `json:"positionValue"`
-		Positions                   []struct {
-			Instrument string `json:"instrument"`
-			Long       struct {
-				Pl           string `json:"pl"`
-				ResettablePL string `json:"resettablePL"`
-				Units        string `json:"units"`
-				UnrealizedPL string `json:"unrealizedPL"`
-			} `json:"long"`
-			Pl           string `json:"pl"`
-			ResettablePL string `json:"resettablePL"`
-			Short        struct {
-				Pl           string `json:"pl"`
-				ResettablePL string `json:"resettablePL"`
-				Units        string `json:"units"`
-				UnrealizedPL string `json:"unrealizedPL"`
-			} `json:"short"`
-			UnrealizedPL string `json:"unrealizedPL"`
-		} `json:"positions"`
-		ResettablePL    string        `json:"resettablePL"`
-		Trades          []interface{} `json:"trades"`
-		UnrealizedPL    string        `json:"unrealizedPL"`
-		WithdrawalLimit string        `json:"withdrawalLimit"`
-	} `json:"account"`
-	LastTransactionID string `json:"lastTransactionID"`
+	Account           Account `json:"account"`
+	LastTransactionID string  `json:"lastTransactionID"`
 }
 
 type AccountSummary struct {
 	Account struct {
-		NAV                         string    `json:"NAV"`
+		NAV                         Decimal   `json:"NAV"`
 		Alias                       string    `json:"alias"`
-		Balance                     float64   `json:"balance,string"`
+		Balance                     Decimal   `json:"balance"`
 		CreatedByUserID             int       `json:"createdByUserID"`
 		CreatedTime                 time.Time `json:"createdTime"`
 		Currency                    string    `json:"currency"`
 		HedgingEnabled              bool      `json:"hedgingEnabled"`
 		ID                          string    `json:"id"`
 		LastTransactionID           string    `json:"lastTransactionID"`
-		MarginAvailable             float64   `json:"marginAvailable,string"`
-		MarginCloseoutMarginUsed    string    `json:"marginCloseoutMarginUsed"`
-		MarginCloseoutNAV           string    `json:"marginCloseoutNAV"`
-		MarginCloseoutPercent       string    `json:"marginCloseoutPercent"`
-		MarginCloseoutPositionValue string    `json:"marginCloseoutPositionValue"`
-		MarginCloseoutUnrealizedPL  string    `json:"marginCloseoutUnrealizedPL"`
-		MarginRate                  string    `json:"marginRate"`
-		MarginUsed                  string    `json:"marginUsed"`
+		MarginAvailable             Decimal   `json:"marginAvailable"`
+		MarginCloseoutMarginUsed    Decimal   `json:"marginCloseoutMarginUsed"`
+		MarginCloseoutNAV           Decimal   `json:"marginCloseoutNAV"`
+		MarginCloseoutPercent       Decimal   `json:"marginCloseoutPercent"`
+		MarginCloseoutPositionValue Decimal   `json:"marginCloseoutPositionValue"`
+		MarginCloseoutUnrealizedPL  Decimal   `json:"marginCloseoutUnrealizedPL"`
+		MarginRate                  Decimal   `json:"marginRate"`
+		MarginUsed                  Decimal   `json:"marginUsed"`
 		OpenPositionCount           int       `json:"openPositionCount"`
 		OpenTradeCount              int       `json:"openTradeCount"`
 		PendingOrderCount           int       `json:"pendingOrderCount"`
-		Pl                          string    `json:"pl"`
-		PositionValue               string    `json:"positionValue"`
-		ResettablePL                string    `json:"resettablePL"`
-		UnrealizedPL                string    `json:"unrealizedPL"`
-		WithdrawalLimit             string    `json:"withdrawalLimit"`
+		Pl                          Decimal   `json:"pl"`
+		PositionValue               Decimal   `json:"positionValue"`
+		ResettablePL                Decimal   `json:"resettablePL"`
+		UnrealizedPL                Decimal   `json:"unrealizedPL"`
+		WithdrawalLimit             Decimal   `json:"withdrawalLimit"`
 	} `json:"account"`
 	LastTransactionID string `json:"lastTransactionID"`
 }
 
-type Instruments []struct {
-	DisplayName                 string `json:"displayName"`
-	DisplayPrecision            int    `json:"displayPrecision"`
-	MarginRate                  string `json:"marginRate"`
-	MaximumOrderUnits           string `json:"maximumOrderUnits"`
-	MaximumPositionSize         string `json:"maximumPositionSize"`
-	MaximumTrailingStopDistance string `json:"maximumTrailingStopDistance"`
-	MinimumTradeSize            string `json:"minimumTradeSize"`
-	MinimumTrailingStopDistance string `json:"minimumTrailingStopDistance"`
-	Name                        string `json:"name"`
-	PipLocation                 int    `json:"pipLocation"`
-	TradeUnitsPrecision         int    `json:"tradeUnitsPrecision"`
-	Type                        string `json:"type"`
+type Instrument struct {
+	DisplayName                 string  `json:"displayName"`
+	DisplayPrecision            int     `json:"displayPrecision"`
+	MarginRate                  Decimal `json:"marginRate"`
+	MaximumOrderUnits           Decimal `json:"maximumOrderUnits"`
+	MaximumPositionSize         Decimal `json:"maximumPositionSize"`
+	MaximumTrailingStopDistance Decimal `json:"maximumTrailingStopDistance"`
+	MinimumTradeSize            Decimal `json:"minimumTradeSize"`
+	MinimumTrailingStopDistance Decimal `json:"minimumTrailingStopDistance"`
+	Name                        string  `json:"name"`
+	PipLocation                 int     `json:"pipLocation"`
+	TradeUnitsPrecision         int     `json:"tradeUnitsPrecision"`
+	Type                        string  `json:"type"`
+}
+
+type Instruments []Instrument
+
+// FormatPrice rounds the price to the precision the instrument is quoted in
+func (i Instrument) FormatPrice(price Decimal) Decimal {
+	return price.Round(i.DisplayPrecision)
+}
+
+// AccountChangeSet is what changed in an account's orders, trades and positions
+// between two transactions
+type AccountChangeSet struct {
+	OrdersCreated   []OrderInfo      `json:"ordersCreated"`
+	OrdersCancelled []OrderInfo      `json:"ordersCancelled"`
+	OrdersFilled    []OrderInfo      `json:"ordersFilled"`
+	OrdersTriggered []OrderInfo      `json:"ordersTriggered"`
+	TradesOpened    []Trade          `json:"tradesOpened"`
+	TradesReduced   []Trade          `json:"tradesReduced"`
+	TradesClosed    []Trade          `json:"tradesClosed"`
+	Positions       []Position       `json:"positions"`
+	Transactions    []AnyTransaction `json:"transactions"`
+}
+
+// AccountChangesState is the price-dependent state of an account, which changes without
+// a transaction being made
+type AccountChangesState struct {
+	NAV                         Decimal                   `json:"NAV"`
+	UnrealizedPL                Decimal                   `json:"unrealizedPL"`
+	MarginUsed                  Decimal                   `json:"marginUsed"`
+	MarginAvailable             Decimal                   `json:"marginAvailable"`
+	PositionValue               Decimal                   `json:"positionValue"`
+	MarginCloseoutUnrealizedPL  Decimal                   `json:"marginCloseoutUnrealizedPL"`
+	MarginCloseoutNAV           Decimal                   `json:"marginCloseoutNAV"`
+	MarginCloseoutMarginUsed    Decimal                   `json:"marginCloseoutMarginUsed"`
+	MarginCloseoutPercent       Decimal                   `json:"marginCloseoutPercent"`
+	MarginCloseoutPositionValue Decimal                   `json:"marginCloseoutPositionValue"`
+	WithdrawalLimit             Decimal                   `json:"withdrawalLimit"`
+	MarginCallMarginUsed        Decimal                   `json:"marginCallMarginUsed,omitempty"`
+	MarginCallPercent           Decimal                   `json:"marginCallPercent,omitempty"`
+	Orders                      []DynamicOrderState       `json:"orders"`
+	Trades                      []CalculatedTradeState    `json:"trades"`
+	Positions                   []CalculatedPositionState `json:"positions"`
+}
+
+// DynamicOrderState is the price-dependent state of a pending order
+type DynamicOrderState struct {
+	ID                     string  `json:"id"`
+	TrailingStopValue      Decimal `json:"trailingStopValue,omitempty"`
+	TriggerDistance        Decimal `json:"triggerDistance,omitempty"`
+	IsTriggerDistanceExact bool    `json:"isTriggerDistanceExact,omitempty"`
+}
+
+// CalculatedTradeState is the price-dependent state of an open trade
+type CalculatedTradeState struct {
+	ID           string  `json:"id"`
+	UnrealizedPL Decimal `json:"unrealizedPL"`
+	MarginUsed   Decimal `json:"marginUsed"`
+}
+
+// CalculatedPositionState is the price-dependent state of a position
+type CalculatedPositionState struct {
+	Instrument        string  `json:"instrument"`
+	NetUnrealizedPL   Decimal `json:"netUnrealizedPL"`
+	LongUnrealizedPL  Decimal `json:"longUnrealizedPL"`
+	ShortUnrealizedPL Decimal `json:"shortUnrealizedPL"`
+	MarginUsed        Decimal `json:"marginUsed"`
 }
 
 type AccountChanges struct {
-	Changes struct {
-		OrdersCancelled []interface{} `json:"ordersCancelled"`
-		OrdersCreated   []interface{} `json:"ordersCreated"`
-		OrdersFilled    []struct {
-			CreateTime           time.Time `json:"createTime"`
-			FilledTime           time.Time `json:"filledTime"`
-			FillingTransactionID string    `json:"fillingTransactionID"`
-			ID                   string    `json:"id"`
-			Instrument           string    `json:"instrument"`
-			PositionFill         string    `json:"positionFill"`
-			State                string    `json:"state"`
-			TimeInForce          string    `json:"timeInForce"`
-			TradeOpenedID        string    `json:"tradeOpenedID"`
-			Type                 string    `json:"type"`
-			Units                string    `json:"units"`
-		} `json:"ordersFilled"`
-		OrdersTriggered []interface{} `json:"ordersTriggered"`
-		Positions       []struct {
-			Instrument string `json:"instrument"`
-			Long       struct {
-				Pl           string `json:"pl"`
-				ResettablePL string `json:"resettablePL"`
-				Units        string `json:"units"`
-			} `json:"long"`
-			Pl           string `json:"pl"`
-			ResettablePL string `json:"resettablePL"`
-			Short        struct {
-				AveragePrice string   `json:"averagePrice"`
-				Pl           string   `json:"pl"`
-				ResettablePL string   `json:"resettablePL"`
-				TradeIDs     []string `json:"tradeIDs"`
-				Units        string   `json:"units"`
-			} `json:"short"`
-		} `json:"positions"`
-		TradesClosed []interface{} `json:"tradesClosed"`
-		TradesOpened []struct {
-			CurrentUnits string    `json:"currentUnits"`
-			Financing    string    `json:"financing"`
-			ID           string    `json:"id"`
-			InitialUnits string    `json:"initialUnits"`
-			Instrument   string    `json:"instrument"`
-			OpenTime     time.Time `json:"openTime"`
-			Price        string    `json:"price"`
-			RealizedPL   string    `json:"realizedPL"`
-			State        string    `json:"state"`
-		} `json:"tradesOpened"`
-		TradesReduced []interface{} `json:"tradesReduced"`
-		Transactions  []struct {
-			AccountID      string    `json:"accountID"`
-			BatchID        string    `json:"batchID"`
-			ID             string    `json:"id"`
-			Instrument     string    `json:"instrument"`
-			PositionFill   string    `json:"positionFill,omitempty"`
-			Reason         string    `json:"reason"`
-			Time           time.Time `json:"time"`
-			TimeInForce    string    `json:"timeInForce,omitempty"`
-			Type           string    `json:"type"`
-			Units          string    `json:"units"`
-			UserID         int       `json:"userID"`
-			AccountBalance string    `json:"accountBalance,omitempty"`
-			Financing      string    `json:"financing,omitempty"`
-			OrderID        string    `json:"orderID,omitempty"`
-			Pl             string    `json:"pl,omitempty"`
-			Price          string    `json:"price,omitempty"`
-			TradeOpened    struct {
-				TradeID string `json:"tradeID"`
-				Units   string `json:"units"`
-			} `json:"tradeOpened,omitempty"`
-		} `json:"transactions"`
-	} `json:"changes"`
-	LastTransactionID string `json:"lastTransactionID"`
-	State             struct {
-		NAV                        string        `json:"NAV"`
-		MarginAvailable            string        `json:"marginAvailable"`
-		MarginCloseoutMarginUsed   string        `json:"marginCloseoutMarginUsed"`
-		MarginCloseoutNAV          string        `json:"marginCloseoutNAV"`
-		MarginCloseoutPercent      string        `json:"marginCloseoutPercent"`
-		MarginCloseoutUnrealizedPL string        `json:"marginCloseoutUnrealizedPL"`
-		MarginUsed                 string        `json:"marginUsed"`
-		Orders                     []interface{} `json:"orders"`
-		PositionValue              string        `json:"positionValue"`
-		Positions                  []struct {
-			Instrument        string `json:"instrument"`
-			LongUnrealizedPL  string `json:"longUnrealizedPL"`
-			NetUnrealizedPL   string `json:"netUnrealizedPL"`
-			ShortUnrealizedPL string `json:"shortUnrealizedPL"`
-		} `json:"positions"`
-		Trades []struct {
-			ID           string `json:"id"`
-			UnrealizedPL string `json:"unrealizedPL"`
-		} `json:"trades"`
-		UnrealizedPL    string `json:"unrealizedPL"`
-		WithdrawalLimit string `json:"withdrawalLimit"`
-	} `json:"state"`
+	Changes           AccountChangeSet    `json:"changes"`
+	LastTransactionID string              `json:"lastTransactionID"`
+	State             AccountChangesState `json:"state"`
 }
 
 type OrderDetails struct {
-	GainPerPipPerMillionUnits float64 `json:"gainPerPipPerMillionUnits,string"`
-	LossPerPipPerMillionUnits float64 `json:"lossPerPipPerMillionUnits,string"`
+	GainPerPipPerMillionUnits Decimal `json:"gainPerPipPerMillionUnits"`
+	LossPerPipPerMillionUnits Decimal `json:"lossPerPipPerMillionUnits"`
 	UnitsAvailable            struct {
 		Default struct {
-			Long  float64 `json:"long,string"`
-			Short float64 `json:"short,string"`
+			Long  Decimal `json:"long"`
+			Short Decimal `json:"short"`
 		} `json:"default"`
 		OpenOnly struct {
-			Long  float64 `json:"long,string"`
-			Short float64 `json:"short,string"`
+			Long  Decimal `json:"long"`
+			Short Decimal `json:"short"`
 		} `json:"openOnly"`
 		ReduceFirst struct {
-			Long  float64 `json:"long,string"`
-			Short float64 `json:"short,string"`
+			Long  Decimal `json:"long"`
+			Short Decimal `json:"short"`
 		} `json:"reduceFirst"`
 		ReduceOnly struct {
-			Long  float64 `json:"long,string"`
-			Short float64 `json:"short,string"`
+			Long  Decimal `json:"long"`
+			Short Decimal `json:"short"`
 		} `json:"reduceOnly"`
 	} `json:"unitsAvailable"`
 	UnitValues struct {
 		Isolation struct {
-			Units               float64 `json:"units,string"`
-			Commission          float64 `json:"commission,string"`
-			PositionValueChange float64 `json:"positionValueChange,string"`
-			PositionValue       float64 `json:"positionValue,string"`
-			MarginRequired      float64 `json:"marginRequired,string"`
-			MarginUsed          float64 `json:"marginUsed,string"`
+			Units               Decimal `json:"units"`
+			Commission          Decimal `json:"commission"`
+			PositionValueChange Decimal `json:"positionValueChange"`
+			PositionValue       Decimal `json:"positionValue"`
+			MarginRequired      Decimal `json:"marginRequired"`
+			MarginUsed          Decimal `json:"marginUsed"`
 		} `json:"isolation"`
 		PositionDefault struct {
-			Units               float64 `json:"units,string"`
-			Commission          float64 `json:"commission,string"`
-			PositionValueChange float64 `json:"positionValueChange,string"`
-			PositionValue       float64 `json:"positionValue,string"`
-			MarginRequired      float64 `json:"marginRequired,string"`
-			MarginUsed          float64 `json:"marginUsed,string"`
+			Units               Decimal `json:"units"`
+			Commission          Decimal `json:"commission"`
+			PositionValueChange Decimal `json:"positionValueChange"`
+			PositionValue       Decimal `json:"positionValue"`
+			MarginRequired      Decimal `json:"marginRequired"`
+			MarginUsed          Decimal `json:"marginUsed"`
 		} `json:"positionDefault"`
 		PositionOpenOnly struct {
-			Units               float64 `json:"units,string"`
-			Commission          float64 `json:"commission,string"`
-			PositionValueChange float64 `json:"positionValueChange,string"`
-			PositionValue       float64 `json:"positionValue,string"`
-			MarginRequired      float64 `json:"marginRequired,string"`
-			MarginUsed          float64 `json:"marginUsed,string"`
+			Units               Decimal `json:"units"`
+			Commission          Decimal `json:"commission"`
+			PositionValueChange Decimal `json:"positionValueChange"`
+			PositionValue       Decimal `json:"positionValue"`
+			MarginRequired      Decimal `json:"marginRequired"`
+			MarginUsed          Decimal `json:"marginUsed"`
 		} `json:"positionOpenOnly"`
 		PositionReduceFirst struct {
-			Units               float64 `json:"units,string"`
-			Commission          float64 `json:"commission,string"`
-			PositionValueChange float64 `json:"positionValueChange,string"`
-			PositionValue       float64 `json:"positionValue,string"`
-			MarginRequired      float64 `json:"marginRequired,string"`
-			MarginUsed          float64 `json:"marginUsed,string"`
+			Units               Decimal `json:"units"`
+			Commission          Decimal `json:"commission"`
+			PositionValueChange Decimal `json:"positionValueChange"`
+			PositionValue       Decimal `json:"positionValue"`
+			MarginRequired      Decimal `json:"marginRequired"`
+			MarginUsed          Decimal `json:"marginUsed"`
 		} `json:"positionReduceFirst"`
 		PositionReduceOnly struct {
-			Units               float64 `json:"units,string"`
-			Commission          float64 `json:"commission,string"`
-			PositionValueChange float64 `json:"positionValueChange,string"`
-			PositionValue       float64 `json:"positionValue,string"`
-			MarginRequired      float64 `json:"marginRequired,string"`
-			MarginUsed          float64 `json:"marginUsed,string"`
+			Units               Decimal `json:"units"`
+			Commission          Decimal `json:"commission"`
+			PositionValueChange Decimal `json:"positionValueChange"`
+			PositionValue       Decimal `json:"positionValue"`
+			MarginRequired      Decimal `json:"marginRequired"`
+			MarginUsed          Decimal `json:"marginUsed"`
 		} `json:"positionReduceOnly"`
 	} `json:"unitValues"`
 	ValueTables struct {
 		CommissionTable []struct {
-			Units string `json:"units"`
-			Value string `json:"value"`
+			Units Decimal `json:"units"`
+			Value Decimal `json:"value"`
 		} `json:"commissionTable"`
 	} `json:"valueTables"`
 	LastTransactionID string `json:"lastTransactionID"`
@@ -280,35 +242,57 @@ type OrderDetails struct {
 
 // Accounts returns a slice of information on accounts authorized for the token.
 func (c *Connection) Accounts() ([]AccountProperties, error) {
+	return c.AccountsContext(context.Background())
+}
+
+// AccountsContext is Accounts bound to the given context
+func (c *Connection) AccountsContext(ctx context.Context) ([]AccountProperties, error) {
 	var ap []AccountProperties
-	err := c.getAndUnmarshal("/accounts", &ap)
+	err := c.getAndUnmarshal(ctx, "/accounts", &ap)
 	return ap, err
 }
 
 // GetAccount returns information on the account.
 func (c *Connection) GetAccount(id string) (AccountInfo, error) {
+	return c.GetAccountContext(context.Background(), id)
+}
+
+// GetAccountContext is GetAccount bound to the given context
+func (c *Connection) GetAccountContext(ctx context.Context, id string) (AccountInfo, error) {
 	ai := AccountInfo{}
-	err := c.getAndUnmarshal("/accounts/"+id, &ai)
+	err := c.getAndUnmarshal(ctx, "/accounts/"+id, &ai)
 	return ai, err
 }
 
-func (c *Connection) GetOrderDetails(instrument string, units string) (OrderDetails, error) {
+func (c *Connection) GetOrderDetails(instrument string, units Decimal) (OrderDetails, error) {
+	return c.GetOrderDetailsContext(context.Background(), instrument, units)
+}
+
+// GetOrderDetailsContext is GetOrderDetails bound to the given context
+func (c *Connection) GetOrderDetailsContext(ctx context.Context, instrument string, units Decimal) (OrderDetails, error) {
 	od := OrderDetails{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/orderEntryData?disableFiltering=true&instrument="+
 			instrument+
 			"&orderPositionFill=DEFAULT&units="+
-			units,
+			units.String(),
 		&od,
 	)
 	return od, err
 }
 
 func (c *Connection) GetAccountSummary() (AccountSummary, error) {
+	return c.GetAccountSummaryContext(context.Background())
+}
+
+// GetAccountSummaryContext is GetAccountSummary bound to the given context
+func (c *Connection) GetAccountSummaryContext(ctx context.Context) (AccountSummary, error) {
 	as := AccountSummary{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/summary",
@@ -318,23 +302,39 @@ func (c *Connection) GetAccountSummary() (AccountSummary, error) {
 }
 
 func (c *Connection) GetAccountInstruments(id string) (Instruments, error) {
+	return c.GetAccountInstrumentsContext(context.Background(), id)
+}
+
+// GetAccountInstrumentsContext is GetAccountInstruments bound to the given context
+func (c *Connection) GetAccountInstrumentsContext(ctx context.Context, id string) (Instruments, error) {
 	var response struct {
 		Instruments Instruments `json:"instruments"`
 	}
 
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			id+
 			"/instruments",
 		&response,
 	)
 
+	if err == nil && id == c.accountID {
+		c.cacheInstruments(response.Instruments)
+	}
+
 	return response.Instruments, err
 }
 
 func (c *Connection) GetAccountChanges(id string, transactionId string) (AccountChanges, error) {
+	return c.GetAccountChangesContext(context.Background(), id, transactionId)
+}
+
+// GetAccountChangesContext is GetAccountChanges bound to the given context
+func (c *Connection) GetAccountChangesContext(ctx context.Context, id string, transactionId string) (AccountChanges, error) {
 	ac := AccountChanges{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			id+
 			"/changes?sinceTransactionID="+
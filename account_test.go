@@ -69,53 +69,7 @@ func TestGetAccount(t *testing.T) {
 		}
 
 		response := AccountInfo{
-			Account: struct {
-				NAV                         string        `json:"NAV"`
-				Alias                       string        `json:"alias"`
-				Balance                     string        `json:"balance"`
-				CreatedByUserID             int           `json:"createdByUserID"`
-				CreatedTime                 time.Time     `json:"createdTime"`
-				Currency                    string        `json:"currency"`
-				HedgingEnabled              bool          `json:"hedgingEnabled"`
-				ID                          string        `json:"id"`
-				LastTransactionID           string        `json:"lastTransactionID"`
-				MarginAvailable             string        `json:"marginAvailable"`
-				MarginCloseoutMarginUsed    string        `json:"marginCloseoutMarginUsed"`
-				MarginCloseoutNAV           string        `json:"marginCloseoutNAV"`
-				MarginCloseoutPercent       string        `json:"marginCloseoutPercent"`
-				MarginCloseoutPositionValue string        `json:"marginCloseoutPositionValue"`
-				MarginCloseoutUnrealizedPL  string        `json:"marginCloseoutUnrealizedPL"`
-				MarginRate                  string        `json:"marginRate"`
-				MarginUsed                  string        `json:"marginUsed"`
-				OpenPositionCount           int           `json:"openPositionCount"`
-				OpenTradeCount              int           `json:"openTradeCount"`
-				Orders                      []interface{} `json:"orders"`
-				PendingOrderCount           int           `json:"pendingOrderCount"`
-				Pl                          string        `json:"pl"`
-				PositionValue               string        `json:"positionValue"`
-				Positions                   []struct {
-					Instrument string `json:"instrument"`
-					Long       struct {
-						Pl           string `json:"pl"`
-						ResettablePL string `json:"resettablePL"`
-						Units        string `json:"units"`
-						UnrealizedPL string `json:"unrealizedPL"`
-					} `json:"long"`
-					Pl           string `json:"pl"`
-					ResettablePL string `json:"resettablePL"`
-					Short        struct {
-						Pl           string `json:"pl"`
-						ResettablePL string `json:"resettablePL"`
-						Units        string `json:"units"`
-						UnrealizedPL string `json:"unrealizedPL"`
-					} `json:"short"`
-					UnrealizedPL string `json:"unrealizedPL"`
-				} `json:"positions"`
-				ResettablePL    string        `json:"resettablePL"`
-				Trades          []interface{} `json:"trades"`
-				UnrealizedPL    string        `json:"unrealizedPL"`
-				WithdrawalLimit string        `json:"withdrawalLimit"`
-			}{
+			Account: Account{
 				ID:                "001-001-1234567-001",
 				NAV:               "43650.78",
 				Balance:           "43650.78",
@@ -174,32 +128,32 @@ func TestGetOrderDetails(t *testing.T) {
 		}
 
 		response := OrderDetails{
-			GainPerPipPerMillionUnits: 10.0,
-			LossPerPipPerMillionUnits: 10.0,
+			GainPerPipPerMillionUnits: "10.0",
+			LossPerPipPerMillionUnits: "10.0",
 			UnitsAvailable: struct {
 				Default struct {
-					Long  float64 `json:"long,string"`
-					Short float64 `json:"short,string"`
+					Long  Decimal `json:"long"`
+					Short Decimal `json:"short"`
 				} `json:"default"`
 				OpenOnly struct {
-					Long  float64 `json:"long,string"`
-					Short float64 `json:"short,string"`
+					Long  Decimal `json:"long"`
+					Short Decimal `json:"short"`
 				} `json:"openOnly"`
 				ReduceFirst struct {
-					Long  float64 `json:"long,string"`
-					Short float64 `json:"short,string"`
+					Long  Decimal `json:"long"`
+					Short Decimal `json:"short"`
 				} `json:"reduceFirst"`
 				ReduceOnly struct {
-					Long  float64 `json:"long,string"`
-					Short float64 `json:"short,string"`
+					Long  Decimal `json:"long"`
+					Short Decimal `json:"short"`
 				} `json:"reduceOnly"`
 			}{
 				Default: struct {
-					Long  float64 `json:"long,string"`
-					Short float64 `json:"short,string"`
+					Long  Decimal `json:"long"`
+					Short Decimal `json:"short"`
 				}{
-					Long:  1000000,
-					Short: 1000000,
+					Long:  "1000000",
+					Short: "1000000",
 				},
 			},
 			LastTransactionID: "1234",
@@ -219,12 +173,12 @@ func TestGetOrderDetails(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	if details.GainPerPipPerMillionUnits != 10.0 {
-		t.Errorf("Expected GainPerPipPerMillionUnits to be 10.0, got %f", details.GainPerPipPerMillionUnits)
+	if details.GainPerPipPerMillionUnits != "10.0" {
+		t.Errorf("Expected GainPerPipPerMillionUnits to be 10.0, got %s", details.GainPerPipPerMillionUnits)
 	}
 
-	if details.UnitsAvailable.Default.Long != 1000000 {
-		t.Errorf("Expected UnitsAvailable.Default.Long to be 1000000, got %f", details.UnitsAvailable.Default.Long)
+	if details.UnitsAvailable.Default.Long != "1000000" {
+		t.Errorf("Expected UnitsAvailable.Default.Long to be 1000000, got %s", details.UnitsAvailable.Default.Long)
 	}
 
 	if details.LastTransactionID != "1234" {
@@ -244,37 +198,37 @@ func TestGetAccountSummary(t *testing.T) {
 
 		response := AccountSummary{
 			Account: struct {
-				NAV                         string    `json:"NAV"`
+				NAV                         Decimal   `json:"NAV"`
 				Alias                       string    `json:"alias"`
-				Balance                     float64   `json:"balance,string"`
+				Balance                     Decimal   `json:"balance"`
 				CreatedByUserID             int       `json:"createdByUserID"`
 				CreatedTime                 time.Time `json:"createdTime"`
 				Currency                    string    `json:"currency"`
 				HedgingEnabled              bool      `json:"hedgingEnabled"`
 				ID                          string    `json:"id"`
 				LastTransactionID           string    `json:"lastTransactionID"`
-				MarginAvailable             float64   `json:"marginAvailable,string"`
-				MarginCloseoutMarginUsed    string    `json:"marginCloseoutMarginUsed"`
-				MarginCloseoutNAV           string    `json:"marginCloseoutNAV"`
-				MarginCloseoutPercent       string    `json:"marginCloseoutPercent"`
-				MarginCloseoutPositionValue string    `json:"marginCloseoutPositionValue"`
-				MarginCloseoutUnrealizedPL  string    `json:"marginCloseoutUnrealizedPL"`
-				MarginRate                  string    `json:"marginRate"`
-				MarginUsed                  string    `json:"marginUsed"`
+				MarginAvailable             Decimal   `json:"marginAvailable"`
+				MarginCloseoutMarginUsed    Decimal   `json:"marginCloseoutMarginUsed"`
+				MarginCloseoutNAV           Decimal   `json:"marginCloseoutNAV"`
+				MarginCloseoutPercent       Decimal   `json:"marginCloseoutPercent"`
+				MarginCloseoutPositionValue Decimal   `json:"marginCloseoutPositionValue"`
+				MarginCloseoutUnrealizedPL  Decimal   `json:"marginCloseoutUnrealizedPL"`
+				MarginRate                  Decimal   `json:"marginRate"`
+				MarginUsed                  Decimal   `json:"marginUsed"`
 				OpenPositionCount           int       `json:"openPositionCount"`
 				OpenTradeCount              int       `json:"openTradeCount"`
 				PendingOrderCount           int       `json:"pendingOrderCount"`
-				Pl                          string    `json:"pl"`
-				PositionValue               string    `json:"positionValue"`
-				ResettablePL                string    `json:"resettablePL"`
-				UnrealizedPL                string    `json:"unrealizedPL"`
-				WithdrawalLimit             string    `json:"withdrawalLimit"`
+				Pl                          Decimal   `json:"pl"`
+				PositionValue               Decimal   `json:"positionValue"`
+				ResettablePL                Decimal   `json:"resettablePL"`
+				UnrealizedPL                Decimal   `json:"unrealizedPL"`
+				WithdrawalLimit             Decimal   `json:"withdrawalLimit"`
 			}{
 				ID:              "001-001-1234567-001",
 				NAV:             "43650.78",
-				Balance:         43650.78,
+				Balance:         "43650.78",
 				Currency:        "USD",
-				MarginAvailable: 43650.78,
+				MarginAvailable: "43650.78",
 			},
 			LastTransactionID: "1234",
 		}
@@ -297,8 +251,8 @@ func TestGetAccountSummary(t *testing.T) {
 		t.Errorf("Expected account ID to be 001-001-1234567-001, got %s", summary.Account.ID)
 	}
 
-	if summary.Account.Balance != 43650.78 {
-		t.Errorf("Expected account balance to be 43650.78, got %f", summary.Account.Balance)
+	if summary.Account.Balance != "43650.78" {
+		t.Errorf("Expected account balance to be 43650.78, got %s", summary.Account.Balance)
 	}
 
 	if summary.LastTransactionID != "1234" {
@@ -383,100 +337,8 @@ func TestGetAccountChanges(t *testing.T) {
 		}
 
 		response := AccountChanges{
-			Changes: struct {
-				OrdersCancelled []interface{} `json:"ordersCancelled"`
-				OrdersCreated   []interface{} `json:"ordersCreated"`
-				OrdersFilled    []struct {
-					CreateTime           time.Time `json:"createTime"`
-					FilledTime           time.Time `json:"filledTime"`
-					FillingTransactionID string    `json:"fillingTransactionID"`
-					ID                   string    `json:"id"`
-					Instrument           string    `json:"instrument"`
-					PositionFill         string    `json:"positionFill"`
-					State                string    `json:"state"`
-					TimeInForce          string    `json:"timeInForce"`
-					TradeOpenedID        string    `json:"tradeOpenedID"`
-					Type                 string    `json:"type"`
-					Units                string    `json:"units"`
-				} `json:"ordersFilled"`
-				OrdersTriggered []interface{} `json:"ordersTriggered"`
-				Positions       []struct {
-					Instrument string `json:"instrument"`
-					Long       struct {
-						Pl           string `json:"pl"`
-						ResettablePL string `json:"resettablePL"`
-						Units        string `json:"units"`
-					} `json:"long"`
-					Pl           string `json:"pl"`
-					ResettablePL string `json:"resettablePL"`
-					Short        struct {
-						AveragePrice string   `json:"averagePrice"`
-						Pl           string   `json:"pl"`
-						ResettablePL string   `json:"resettablePL"`
-						TradeIDs     []string `json:"tradeIDs"`
-						Units        string   `json:"units"`
-					} `json:"short"`
-				} `json:"positions"`
-				TradesClosed []interface{} `json:"tradesClosed"`
-				TradesOpened []struct {
-					CurrentUnits string    `json:"currentUnits"`
-					Financing    string    `json:"financing"`
-					ID           string    `json:"id"`
-					InitialUnits string    `json:"initialUnits"`
-					Instrument   string    `json:"instrument"`
-					OpenTime     time.Time `json:"openTime"`
-					Price        string    `json:"price"`
-					RealizedPL   string    `json:"realizedPL"`
-					State        string    `json:"state"`
-				} `json:"tradesOpened"`
-				TradesReduced []interface{} `json:"tradesReduced"`
-				Transactions  []struct {
-					AccountID      string    `json:"accountID"`
-					BatchID        string    `json:"batchID"`
-					ID             string    `json:"id"`
-					Instrument     string    `json:"instrument"`
-					PositionFill   string    `json:"positionFill,omitempty"`
-					Reason         string    `json:"reason"`
-					Time           time.Time `json:"time"`
-					TimeInForce    string    `json:"timeInForce,omitempty"`
-					Type           string    `json:"type"`
-					Units          string    `json:"units"`
-					UserID         int       `json:"userID"`
-					AccountBalance string    `json:"accountBalance,omitempty"`
-					Financing      string    `json:"financing,omitempty"`
-					OrderID        string    `json:"orderID,omitempty"`
-					Pl             string    `json:"pl,omitempty"`
-					Price          string    `json:"price,omitempty"`
-					TradeOpened    struct {
-						TradeID string `json:"tradeID"`
-						Units   string `json:"units"`
-					} `json:"tradeOpened,omitempty"`
-				} `json:"transactions"`
-			}{}, // Add this comma
 			LastTransactionID: "1235",
-			State: struct {
-				NAV                        string        `json:"NAV"`
-				MarginAvailable            string        `json:"marginAvailable"`
-				MarginCloseoutMarginUsed   string        `json:"marginCloseoutMarginUsed"`
-				MarginCloseoutNAV          string        `json:"marginCloseoutNAV"`
-				MarginCloseoutPercent      string        `json:"marginCloseoutPercent"`
-				MarginCloseoutUnrealizedPL string        `json:"marginCloseoutUnrealizedPL"`
-				MarginUsed                 string        `json:"marginUsed"`
-				Orders                     []interface{} `json:"orders"`
-				PositionValue              string        `json:"positionValue"`
-				Positions                  []struct {
-					Instrument        string `json:"instrument"`
-					LongUnrealizedPL  string `json:"longUnrealizedPL"`
-					NetUnrealizedPL   string `json:"netUnrealizedPL"`
-					ShortUnrealizedPL string `json:"shortUnrealizedPL"`
-				} `json:"positions"`
-				Trades []struct {
-					ID           string `json:"id"`
-					UnrealizedPL string `json:"unrealizedPL"`
-				} `json:"trades"`
-				UnrealizedPL    string `json:"unrealizedPL"`
-				WithdrawalLimit string `json:"withdrawalLimit"`
-			}{
+			State: AccountChangesState{
 				NAV:             "10000.00",
 				MarginAvailable: "9000.00",
 			},
@@ -0,0 +1,273 @@
+package goanda
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Supporting OANDA docs - https://developer.oanda.com/rest-live-v20/development-guide/#Polling_for_Account_Changes
+
+const defaultAccountSyncInterval = 5 * time.Second
+
+// AccountSync keeps an in-memory copy of the connection's account up to date. It loads
+// the account with GetAccount, then polls GetAccountChanges from the last transaction it
+// has applied, as OANDA recommends, so the copy never has to be fetched again in full.
+//
+// An AccountSync is safe for concurrent use. Readers get a consistent copy of the account
+// through Snapshot while Run or Sync apply changes.
+type AccountSync struct {
+	c        *Connection
+	interval time.Duration
+
+	// syncMu serialises Sync, so the same changes are never applied twice
+	syncMu sync.Mutex
+
+	mu      sync.RWMutex
+	account Account
+	loaded  bool
+}
+
+// NewAccountSync returns an AccountSync for the connection's account, polling for
+// changes every interval. A zero interval polls every 5 seconds.
+func (c *Connection) NewAccountSync(interval time.Duration) *AccountSync {
+	if interval <= 0 {
+		interval = defaultAccountSyncInterval
+	}
+	return &AccountSync{c: c, interval: interval}
+}
+
+// Run loads the account and applies its changes every interval until the context is
+// cancelled or a request fails. It returns the context's error or the request's error.
+// Calling Run again after an error resumes from the last transaction applied.
+func (s *AccountSync) Run(ctx context.Context) error {
+	if err := s.Sync(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Sync loads the account the first time it is called, and after that applies the changes
+// made to the account since the last transaction applied
+func (s *AccountSync) Sync(ctx context.Context) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	s.mu.RLock()
+	loaded, since := s.loaded, s.account.LastTransactionID
+	s.mu.RUnlock()
+
+	if !loaded {
+		info, err := s.c.GetAccountContext(ctx, s.c.accountID)
+		if err != nil {
+			return err
+		}
+		info.Account.LastTransactionID = info.LastTransactionID
+
+		s.mu.Lock()
+		s.account = info.Account
+		s.loaded = true
+		s.mu.Unlock()
+		return nil
+	}
+
+	changes, err := s.c.GetAccountChangesContext(ctx, s.c.accountID, since)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.account.applyChanges(changes)
+	s.mu.Unlock()
+	return nil
+}
+
+// Snapshot returns a copy of the account as of the last transaction applied, and false
+// if the account hasn't been loaded yet. The copy is not changed by later syncs.
+func (s *AccountSync) Snapshot() (Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account := s.account
+	account.Orders = append([]OrderInfo(nil), s.account.Orders...)
+	account.Trades = append([]Trade(nil), s.account.Trades...)
+	account.Positions = append([]Position(nil), s.account.Positions...)
+	return account, s.loaded
+}
+
+// LastTransactionID returns the ID of the last transaction applied to the account
+func (s *AccountSync) LastTransactionID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.account.LastTransactionID
+}
+
+// applyChanges brings the account up to date with the changes and state returned by
+// GetAccountChanges
+func (a *Account) applyChanges(changes AccountChanges) {
+	set := changes.Changes
+
+	done := make(map[string]bool)
+	for _, list := range [][]OrderInfo{set.OrdersCancelled, set.OrdersFilled, set.OrdersTriggered} {
+		for _, o := range list {
+			done[o.ID] = true
+		}
+	}
+	orders := make([]OrderInfo, 0, len(a.Orders)+len(set.OrdersCreated))
+	for _, o := range a.Orders {
+		if !done[o.ID] {
+			orders = append(orders, o)
+		}
+	}
+	for _, o := range set.OrdersCreated {
+		if !done[o.ID] {
+			orders = replaceOrder(orders, o)
+		}
+	}
+	a.Orders = orders
+
+	closed := make(map[string]bool)
+	for _, t := range set.TradesClosed {
+		closed[t.ID] = true
+	}
+	trades := make([]Trade, 0, len(a.Trades)+len(set.TradesOpened))
+	for _, t := range a.Trades {
+		if !closed[t.ID] {
+			trades = append(trades, t)
+		}
+	}
+	for _, list := range [][]Trade{set.TradesOpened, set.TradesReduced} {
+		for _, t := range list {
+			if !closed[t.ID] {
+				trades = replaceTrade(trades, t)
+			}
+		}
+	}
+	a.Trades = trades
+
+	for _, p := range set.Positions {
+		a.Positions = replacePosition(a.Positions, p)
+	}
+
+	for _, t := range set.Transactions {
+		switch t := t.TypedTransaction.(type) {
+		case *OrderFillTransaction:
+			a.Balance = t.AccountBalance
+			a.Pl = a.Pl.Add(t.PL)
+			a.ResettablePL = a.ResettablePL.Add(t.PL)
+		case *TransferFundsTransaction:
+			a.Balance = t.AccountBalance
+		case *DailyFinancingTransaction:
+			a.Balance = t.AccountBalance
+		case *DividendAdjustmentTransaction:
+			a.Balance = t.AccountBalance
+		case *ResetResettablePLTransaction:
+			a.ResettablePL = "0"
+		}
+	}
+
+	a.applyState(changes.State)
+
+	a.PendingOrderCount = len(a.Orders)
+	a.OpenTradeCount = len(a.Trades)
+	a.OpenPositionCount = 0
+	for _, p := range a.Positions {
+		if !p.Long.Units.IsZero() || !p.Short.Units.IsZero() {
+			a.OpenPositionCount++
+		}
+	}
+	if changes.LastTransactionID != "" {
+		a.LastTransactionID = changes.LastTransactionID
+	}
+}
+
+// applyState updates the account's price-dependent values. Values missing from the
+// state are left as they were.
+func (a *Account) applyState(state AccountChangesState) {
+	update := func(field *Decimal, value Decimal) {
+		if value != "" {
+			*field = value
+		}
+	}
+
+	update(&a.NAV, state.NAV)
+	update(&a.UnrealizedPL, state.UnrealizedPL)
+	update(&a.MarginUsed, state.MarginUsed)
+	update(&a.MarginAvailable, state.MarginAvailable)
+	update(&a.PositionValue, state.PositionValue)
+	update(&a.MarginCloseoutUnrealizedPL, state.MarginCloseoutUnrealizedPL)
+	update(&a.MarginCloseoutNAV, state.MarginCloseoutNAV)
+	update(&a.MarginCloseoutMarginUsed, state.MarginCloseoutMarginUsed)
+	update(&a.MarginCloseoutPercent, state.MarginCloseoutPercent)
+	update(&a.MarginCloseoutPositionValue, state.MarginCloseoutPositionValue)
+	update(&a.WithdrawalLimit, state.WithdrawalLimit)
+
+	for _, s := range state.Orders {
+		for i := range a.Orders {
+			if a.Orders[i].ID == s.ID {
+				update(&a.Orders[i].TrailingStopValue, s.TrailingStopValue)
+			}
+		}
+	}
+	for _, s := range state.Trades {
+		for i := range a.Trades {
+			if a.Trades[i].ID == s.ID {
+				update(&a.Trades[i].UnrealizedPL, s.UnrealizedPL)
+				update(&a.Trades[i].MarginUsed, s.MarginUsed)
+			}
+		}
+	}
+	for _, s := range state.Positions {
+		for i := range a.Positions {
+			if a.Positions[i].Instrument == s.Instrument {
+				update(&a.Positions[i].UnrealizedPL, s.NetUnrealizedPL)
+				update(&a.Positions[i].Long.UnrealizedPL, s.LongUnrealizedPL)
+				update(&a.Positions[i].Short.UnrealizedPL, s.ShortUnrealizedPL)
+				update(&a.Positions[i].MarginUsed, s.MarginUsed)
+			}
+		}
+	}
+}
+
+func replaceOrder(orders []OrderInfo, order OrderInfo) []OrderInfo {
+	for i := range orders {
+		if orders[i].ID == order.ID {
+			orders[i] = order
+			return orders
+		}
+	}
+	return append(orders, order)
+}
+
+func replaceTrade(trades []Trade, trade Trade) []Trade {
+	for i := range trades {
+		if trades[i].ID == trade.ID {
+			trades[i] = trade
+			return trades
+		}
+	}
+	return append(trades, trade)
+}
+
+func replacePosition(positions []Position, position Position) []Position {
+	for i := range positions {
+		if positions[i].Instrument == position.Instrument {
+			positions[i] = position
+			return positions
+		}
+	}
+	return append(positions, position)
+}
@@ -0,0 +1,187 @@
+package goanda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccountSync(t *testing.T) {
+	defer logTestResult(t, "AccountSync")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/test-account":
+			w.Write([]byte(`{
+				"account": {
+					"id": "test-account",
+					"balance": "1000.00",
+					"NAV": "1000.00",
+					"pl": "0",
+					"orders": [
+						{"id": "5", "type": "LIMIT", "instrument": "EUR_USD", "units": "100", "price": "1.10000", "state": "PENDING"},
+						{"id": "6", "type": "LIMIT", "instrument": "USD_JPY", "units": "-50", "price": "150.000", "state": "PENDING"}
+					],
+					"trades": [
+						{"id": "3", "instrument": "GBP_USD", "currentUnits": "200", "price": "1.25000", "state": "OPEN"}
+					],
+					"positions": [
+						{"instrument": "GBP_USD", "long": {"units": "200"}, "short": {"units": "0"}}
+					],
+					"pendingOrderCount": 2,
+					"openTradeCount": 1,
+					"openPositionCount": 1
+				},
+				"lastTransactionID": "10"
+			}`))
+		case "/accounts/test-account/changes":
+			if since := r.URL.Query().Get("sinceTransactionID"); since != "10" {
+				t.Errorf("Expected sinceTransactionID to be 10, got %s", since)
+			}
+			w.Write([]byte(`{
+				"changes": {
+					"ordersCreated": [
+						{"id": "12", "type": "STOP_LOSS", "tradeID": "11", "price": "1.09000", "state": "PENDING"}
+					],
+					"ordersFilled": [
+						{"id": "5", "type": "LIMIT", "instrument": "EUR_USD", "units": "100", "state": "FILLED", "tradeOpenedID": "11"}
+					],
+					"ordersCancelled": [
+						{"id": "6", "type": "LIMIT", "instrument": "USD_JPY", "units": "-50", "state": "CANCELLED"}
+					],
+					"tradesOpened": [
+						{"id": "11", "instrument": "EUR_USD", "currentUnits": "100", "price": "1.10000", "state": "OPEN"}
+					],
+					"tradesClosed": [
+						{"id": "3", "instrument": "GBP_USD", "currentUnits": "0", "state": "CLOSED"}
+					],
+					"positions": [
+						{"instrument": "GBP_USD", "pl": "12.50", "long": {"units": "0"}, "short": {"units": "0"}},
+						{"instrument": "EUR_USD", "long": {"units": "100", "averagePrice": "1.10000"}, "short": {"units": "0"}}
+					],
+					"transactions": [
+						{"id": "11", "type": "ORDER_FILL", "orderID": "5", "pl": "0", "accountBalance": "1000.00"},
+						{"id": "13", "type": "ORDER_FILL", "orderID": "14", "pl": "12.50", "accountBalance": "1012.50"}
+					]
+				},
+				"state": {
+					"NAV": "1013.00",
+					"unrealizedPL": "0.50",
+					"trades": [{"id": "11", "unrealizedPL": "0.50", "marginUsed": "3.30"}],
+					"positions": [{"instrument": "EUR_USD", "netUnrealizedPL": "0.50", "longUnrealizedPL": "0.50", "shortUnrealizedPL": "0"}]
+				},
+				"lastTransactionID": "14"
+			}`))
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	s := c.NewAccountSync(time.Minute)
+	if _, ok := s.Snapshot(); ok {
+		t.Fatal("Expected no snapshot before the account is loaded")
+	}
+
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	before, ok := s.Snapshot()
+	if !ok {
+		t.Fatal("Expected a snapshot after the account is loaded")
+	}
+	if before.LastTransactionID != "10" || len(before.Orders) != 2 || len(before.Trades) != 1 {
+		t.Fatalf("Unexpected account after loading: %+v", before)
+	}
+
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	account, _ := s.Snapshot()
+
+	if account.LastTransactionID != "14" || s.LastTransactionID() != "14" {
+		t.Errorf("Expected LastTransactionID to be 14, got %s", account.LastTransactionID)
+	}
+	if len(account.Orders) != 1 || account.Orders[0].ID != "12" || account.PendingOrderCount != 1 {
+		t.Errorf("Expected only the stop loss order to be pending, got %+v", account.Orders)
+	}
+	if len(account.Trades) != 1 || account.Trades[0].ID != "11" || account.OpenTradeCount != 1 {
+		t.Fatalf("Expected only trade 11 to be open, got %+v", account.Trades)
+	}
+	if account.Trades[0].UnrealizedPL != "0.50" || account.Trades[0].MarginUsed != "3.30" {
+		t.Errorf("Expected the trade's state to be applied, got %+v", account.Trades[0])
+	}
+	if len(account.Positions) != 2 || account.OpenPositionCount != 1 {
+		t.Errorf("Expected one open position of two, got %+v", account.Positions)
+	}
+	if account.Positions[0].PL != "12.50" || account.Positions[1].Long.UnrealizedPL != "0.50" {
+		t.Errorf("Expected the positions to be updated, got %+v", account.Positions)
+	}
+	if account.Balance != "1012.50" || account.Pl != "12.50" {
+		t.Errorf("Expected balance 1012.50 and pl 12.50, got %s and %s", account.Balance, account.Pl)
+	}
+	if account.NAV != "1013.00" || account.UnrealizedPL != "0.50" {
+		t.Errorf("Expected NAV 1013.00 and unrealizedPL 0.50, got %s and %s", account.NAV, account.UnrealizedPL)
+	}
+
+	if before.LastTransactionID != "10" || len(before.Orders) != 2 || before.Orders[0].ID != "5" {
+		t.Errorf("Expected the earlier snapshot to be unchanged, got %+v", before)
+	}
+}
+
+func TestAccountSyncRun(t *testing.T) {
+	defer logTestResult(t, "AccountSyncRun")
+
+	polled := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/test-account":
+			w.Write([]byte(`{"account": {"id": "test-account"}, "lastTransactionID": "1"}`))
+		case "/accounts/test-account/changes":
+			select {
+			case polled <- r.URL.Query().Get("sinceTransactionID"):
+			default:
+			}
+			w.Write([]byte(`{"changes": {}, "state": {}, "lastTransactionID": "2"}`))
+		}
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- c.NewAccountSync(10 * time.Millisecond).Run(ctx)
+	}()
+
+	for _, want := range []string{"1", "2"} {
+		select {
+		case since := <-polled:
+			if since != want {
+				t.Errorf("Expected sinceTransactionID %s, got %s", want, since)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for a poll")
+		}
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package goanda
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, used for every price, amount of units and
+// amount of money exchanged with the api.
+//
+// The v20 api transmits these values as strings (the PriceValue, DecimalNumber and
+// AccountUnits types of the spec), and Decimal keeps that representation so values
+// round trip through JSON unchanged and compare equal to the literals the api uses.
+// The zero value is the empty string, which means the value was not set and is
+// omitted from requests by fields tagged omitempty. In arithmetic it counts as zero.
+//
+// Arithmetic is exact, and never goes through float64. Calling an arithmetic method
+// on a malformed Decimal panics; values decoded from JSON or returned by ParseDecimal
+// are always well formed.
+type Decimal string
+
+// ParseDecimal parses a decimal number such as "-1.2345" or "1e-5"
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+
+	unscaled, scale, err := parseDecimal(s)
+	if err != nil {
+		return "", err
+	}
+	return formatDecimal(unscaled, scale), nil
+}
+
+// DecimalFromInt returns the integer as a Decimal
+func DecimalFromInt(i int64) Decimal {
+	return Decimal(strconv.FormatInt(i, 10))
+}
+
+// DecimalFromFloat returns the float rounded to the given number of decimal places
+func DecimalFromFloat(f float64, places int) Decimal {
+	return Decimal(strconv.FormatFloat(f, 'f', places, 64))
+}
+
+// String returns the decimal as sent to the api
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Valid reports whether the decimal is well formed. The empty decimal is valid.
+func (d Decimal) Valid() bool {
+	if d == "" {
+		return true
+	}
+	_, _, err := parseDecimal(string(d))
+	return err == nil
+}
+
+// IsZero reports whether the decimal is unset or equal to zero
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the decimal
+func (d Decimal) Sign() int {
+	unscaled, _ := d.must()
+	return unscaled.Sign()
+}
+
+// Cmp compares the decimals, returning -1, 0 or +1
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal reports whether the decimals have the same value, regardless of their
+// number of decimal places
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Places returns the number of digits after the decimal point
+func (d Decimal) Places() int {
+	_, scale := d.must()
+	return scale
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return formatDecimal(a.Add(a, b), scale)
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return formatDecimal(a.Sub(a, b), scale)
+}
+
+// Mul returns d * o
+func (d Decimal) Mul(o Decimal) Decimal {
+	a, as := d.must()
+	b, bs := o.must()
+	return formatDecimal(a.Mul(a, b), as+bs)
+}
+
+// Quo returns d / o rounded half away from zero to the given number of places.
+// It panics if o is zero.
+func (d Decimal) Quo(o Decimal, places int) Decimal {
+	a, as := d.must()
+	b, bs := o.must()
+	if b.Sign() == 0 {
+		panic("goanda: decimal division by zero")
+	}
+
+	// a/10^as / b/10^bs = a*10^(bs+places+1-as) / b / 10^(places+1)
+	shift := bs + places + 1 - as
+	if shift >= 0 {
+		a.Mul(a, pow10(shift))
+	} else {
+		b.Mul(b, pow10(-shift))
+	}
+	q := a.Quo(a, b)
+	return formatDecimal(roundUnscaled(q, places+1, places), places)
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	unscaled, scale := d.must()
+	return formatDecimal(unscaled.Neg(unscaled), scale)
+}
+
+// Abs returns the absolute value of d
+func (d Decimal) Abs() Decimal {
+	unscaled, scale := d.must()
+	return formatDecimal(unscaled.Abs(unscaled), scale)
+}
+
+// Round returns d rounded half away from zero to exactly the given number of decimal
+// places, padding with zeros if required. Round(0) returns an integer.
+func (d Decimal) Round(places int) Decimal {
+	unscaled, scale := d.must()
+	if places < 0 {
+		places = 0
+	}
+	return formatDecimal(roundUnscaled(unscaled, scale, places), places)
+}
+
+// Truncate returns d with any digits beyond the given number of decimal places dropped
+func (d Decimal) Truncate(places int) Decimal {
+	unscaled, scale := d.must()
+	if places < 0 {
+		places = 0
+	}
+	if places >= scale {
+		return formatDecimal(unscaled.Mul(unscaled, pow10(places-scale)), places)
+	}
+	return formatDecimal(unscaled.Quo(unscaled, pow10(scale-places)), places)
+}
+
+// Float64 returns the nearest float64 to d, for use where exactness doesn't matter
+func (d Decimal) Float64() float64 {
+	if d == "" {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(string(d), 64)
+	return f
+}
+
+// UnmarshalJSON accepts both JSON strings and numbers
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) must() (*big.Int, int) {
+	if d == "" {
+		return new(big.Int), 0
+	}
+	unscaled, scale, err := parseDecimal(string(d))
+	if err != nil {
+		panic(err)
+	}
+	return unscaled, scale
+}
+
+var errInvalidDecimal = errors.New("invalid decimal")
+
+// maxDecimalExponent bounds the exponent a decimal may be written with, as the digits
+// it stands for are allocated in full
+const maxDecimalExponent = 1000
+
+// parseDecimal returns the decimal as an unscaled integer and the number of places
+// it is scaled by
+func parseDecimal(s string) (*big.Int, int, error) {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			return nil, 0, fmt.Errorf("%w: %q", errInvalidDecimal, s)
+		}
+		mantissa, exponent = s[:i], e
+	}
+
+	sign := ""
+	if mantissa != "" && (mantissa[0] == '-' || mantissa[0] == '+') {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+
+	whole, frac := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		whole, frac = mantissa[:i], mantissa[i+1:]
+	}
+	if whole == "" && frac == "" {
+		return nil, 0, fmt.Errorf("%w: %q", errInvalidDecimal, s)
+	}
+
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return nil, 0, fmt.Errorf("%w: %q", errInvalidDecimal, s)
+		}
+	}
+
+	unscaled, ok := new(big.Int).SetString(sign+whole+frac, 10)
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %q", errInvalidDecimal, s)
+	}
+
+	scale := len(frac) - exponent
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return unscaled, scale, nil
+}
+
+func formatDecimal(unscaled *big.Int, scale int) Decimal {
+	digits := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if unscaled.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return Decimal(digits)
+}
+
+// align returns both decimals scaled to the larger of their number of places
+func align(d, o Decimal) (*big.Int, *big.Int, int) {
+	a, as := d.must()
+	b, bs := o.must()
+	if as < bs {
+		a.Mul(a, pow10(bs-as))
+		return a, b, bs
+	}
+	b.Mul(b, pow10(as-bs))
+	return a, b, as
+}
+
+// roundUnscaled rounds an unscaled integer from one scale to another, half away from zero
+func roundUnscaled(unscaled *big.Int, from, to int) *big.Int {
+	if to >= from {
+		return unscaled.Mul(unscaled, pow10(to-from))
+	}
+
+	divisor := pow10(from - to)
+	q, r := new(big.Int).QuoRem(unscaled, divisor, new(big.Int))
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(divisor) >= 0 {
+		if unscaled.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,145 @@
+package goanda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	defer logTestResult(t, "ParseDecimal")
+
+	tests := []struct {
+		in   string
+		want Decimal
+		err  bool
+	}{
+		{"1.10000", "1.10000", false},
+		{"-0.5", "-0.5", false},
+		{"+2", "2", false},
+		{".25", "0.25", false},
+		{"1e-5", "0.00001", false},
+		{"1.5E3", "1500", false},
+		{" 42 ", "42", false},
+		{"", "", false},
+		{"abc", "", true},
+		{"1.2.3", "", true},
+		{"-", "", true},
+		{"1e", "", true},
+		{"1e-1000000000", "", true},
+		{"1e1001", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseDecimal(%q) error = %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDecimal(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	defer logTestResult(t, "DecimalArithmetic")
+
+	tests := []struct {
+		name string
+		got  Decimal
+		want Decimal
+	}{
+		{"add", Decimal("0.1").Add("0.2"), "0.3"},
+		{"add scales", Decimal("1.10000").Add("2.5"), "3.60000"},
+		{"add unset", Decimal("").Add("1.5"), "1.5"},
+		{"sub", Decimal("1.0").Sub("1.25"), "-0.25"},
+		{"mul", Decimal("1.12345").Mul("-10000"), "-11234.50000"},
+		{"quo", Decimal("10").Quo("3", 4), "3.3333"},
+		{"quo rounds", Decimal("2").Quo("3", 2), "0.67"},
+		{"quo negative", Decimal("-2").Quo("3", 2), "-0.67"},
+		{"neg", Decimal("1.5").Neg(), "-1.5"},
+		{"abs", Decimal("-1.5").Abs(), "1.5"},
+		{"round half up", Decimal("1.234565").Round(5), "1.23457"},
+		{"round half away from zero", Decimal("-1.234565").Round(5), "-1.23457"},
+		{"round pads", Decimal("1.2").Round(4), "1.2000"},
+		{"round integer", Decimal("99.5").Round(0), "100"},
+		{"truncate", Decimal("-1.239").Truncate(2), "-1.23"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDecimalCompare(t *testing.T) {
+	defer logTestResult(t, "DecimalCompare")
+
+	if !Decimal("1.10").Equal("1.1") {
+		t.Error("Expected 1.10 to equal 1.1")
+	}
+	if Decimal("1.10").Cmp("1.11") != -1 {
+		t.Error("Expected 1.10 to be less than 1.11")
+	}
+	if Decimal("-2").Cmp("1") != -1 {
+		t.Error("Expected -2 to be less than 1")
+	}
+	if !Decimal("").IsZero() || !Decimal("0.000").IsZero() || Decimal("0.001").IsZero() {
+		t.Error("Unexpected IsZero result")
+	}
+	if Decimal("-0.5").Sign() != -1 {
+		t.Error("Expected -0.5 to be negative")
+	}
+	if Decimal("1.23400").Places() != 5 {
+		t.Errorf("Expected 5 places, got %d", Decimal("1.23400").Places())
+	}
+	if Decimal("1.2.3").Valid() || !Decimal("").Valid() {
+		t.Error("Unexpected Valid result")
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	defer logTestResult(t, "DecimalJSON")
+
+	var v struct {
+		Price    Decimal `json:"price"`
+		Units    Decimal `json:"units"`
+		Distance Decimal `json:"distance,omitempty"`
+		Missing  Decimal `json:"missing"`
+	}
+
+	err := json.Unmarshal([]byte(`{"price":"1.10000","units":-100,"missing":null}`), &v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v.Price != "1.10000" || v.Units != "-100" || v.Missing != "" {
+		t.Errorf("Unexpected decoded values: %+v", v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(b) != `{"price":"1.10000","units":"-100","missing":""}` {
+		t.Errorf("Unexpected encoding: %s", b)
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":"one"}`), &v); err == nil {
+		t.Error("Expected an error decoding a malformed decimal")
+	}
+}
+
+func TestInstrumentFormatPrice(t *testing.T) {
+	defer logTestResult(t, "InstrumentFormatPrice")
+
+	eurusd := Instrument{Name: "EUR_USD", DisplayPrecision: 5}
+	if got := eurusd.FormatPrice(Decimal("1.1").Mul("1.01")); got != "1.11100" {
+		t.Errorf("Expected 1.11100, got %s", got)
+	}
+
+	usdjpy := Instrument{Name: "USD_JPY", DisplayPrecision: 3}
+	if got := usdjpy.FormatPrice("151.23456"); got != "151.235" {
+		t.Errorf("Expected 151.235, got %s", got)
+	}
+}
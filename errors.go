@@ -2,42 +2,202 @@ package goanda
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Sentinel errors that an APIError can be matched against with errors.Is
+var (
+	ErrBadRequest         = errors.New("goanda: bad request")
+	ErrUnauthorized       = errors.New("goanda: unauthorized")
+	ErrForbidden          = errors.New("goanda: forbidden")
+	ErrNotFound           = errors.New("goanda: not found")
+	ErrRateLimited        = errors.New("goanda: rate limited")
+	ErrInsufficientMargin = errors.New("goanda: insufficient margin")
+	ErrMarketHalted       = errors.New("goanda: market halted")
+)
+
+// RejectReason is the reason the api gives for rejecting a transaction
+//
+// Supporting OANDA docs - http://developer.oanda.com/rest-live-v20/transaction-df/#TransactionRejectReason
+type RejectReason string
+
+// Commonly encountered reject reasons
+const (
+	RejectInternalServerError                   RejectReason = "INTERNAL_SERVER_ERROR"
+	RejectInstrumentPriceUnknown                RejectReason = "INSTRUMENT_PRICE_UNKNOWN"
+	RejectAccountNotActive                      RejectReason = "ACCOUNT_NOT_ACTIVE"
+	RejectAccountLocked                         RejectReason = "ACCOUNT_LOCKED"
+	RejectAccountOrderCreationLocked            RejectReason = "ACCOUNT_ORDER_CREATION_LOCKED"
+	RejectAccountOrderCancelLocked              RejectReason = "ACCOUNT_ORDER_CANCEL_LOCKED"
+	RejectInstrumentNotTradeable                RejectReason = "INSTRUMENT_NOT_TRADEABLE"
+	RejectPendingOrdersAllowedExceeded          RejectReason = "PENDING_ORDERS_ALLOWED_EXCEEDED"
+	RejectOrderDoesntExist                      RejectReason = "ORDER_DOESNT_EXIST"
+	RejectOrderIdentifierInconsistency          RejectReason = "ORDER_IDENTIFIER_INCONSISTENCY"
+	RejectTradeDoesntExist                      RejectReason = "TRADE_DOESNT_EXIST"
+	RejectTradeIdentifierInconsistency          RejectReason = "TRADE_IDENTIFIER_INCONSISTENCY"
+	RejectInsufficientMargin                    RejectReason = "INSUFFICIENT_MARGIN"
+	RejectInsufficientLiquidity                 RejectReason = "INSUFFICIENT_LIQUIDITY"
+	RejectInstrumentMissing                     RejectReason = "INSTRUMENT_MISSING"
+	RejectInstrumentUnknown                     RejectReason = "INSTRUMENT_UNKNOWN"
+	RejectUnitsMissing                          RejectReason = "UNITS_MISSING"
+	RejectUnitsInvalid                          RejectReason = "UNITS_INVALID"
+	RejectUnitsPrecisionExceeded                RejectReason = "UNITS_PRECISION_EXCEEDED"
+	RejectUnitsLimitExceeded                    RejectReason = "UNITS_LIMIT_EXCEEDED"
+	RejectUnitsMinimumNotMet                    RejectReason = "UNITS_MINIMUM_NOT_MET"
+	RejectPriceMissing                          RejectReason = "PRICE_MISSING"
+	RejectPriceInvalid                          RejectReason = "PRICE_INVALID"
+	RejectPricePrecisionExceeded                RejectReason = "PRICE_PRECISION_EXCEEDED"
+	RejectPriceDistanceMissing                  RejectReason = "PRICE_DISTANCE_MISSING"
+	RejectPriceDistanceInvalid                  RejectReason = "PRICE_DISTANCE_INVALID"
+	RejectPriceDistancePrecisionExceeded        RejectReason = "PRICE_DISTANCE_PRECISION_EXCEEDED"
+	RejectPriceDistanceMaximumExceeded          RejectReason = "PRICE_DISTANCE_MAXIMUM_EXCEEDED"
+	RejectPriceDistanceMinimumNotMet            RejectReason = "PRICE_DISTANCE_MINIMUM_NOT_MET"
+	RejectTimeInForceMissing                    RejectReason = "TIME_IN_FORCE_MISSING"
+	RejectTimeInForceInvalid                    RejectReason = "TIME_IN_FORCE_INVALID"
+	RejectTimeInForceGTDTimestampMissing        RejectReason = "TIME_IN_FORCE_GTD_TIMESTAMP_MISSING"
+	RejectTimeInForceGTDTimestampInPast         RejectReason = "TIME_IN_FORCE_GTD_TIMESTAMP_IN_PAST"
+	RejectPriceBoundInvalid                     RejectReason = "PRICE_BOUND_INVALID"
+	RejectPriceBoundPrecisionExceeded           RejectReason = "PRICE_BOUND_PRECISION_EXCEEDED"
+	RejectClientOrderIDInvalid                  RejectReason = "CLIENT_ORDER_ID_INVALID"
+	RejectClientOrderIDAlreadyExists            RejectReason = "CLIENT_ORDER_ID_ALREADY_EXISTS"
+	RejectClientOrderTagInvalid                 RejectReason = "CLIENT_ORDER_TAG_INVALID"
+	RejectClientOrderCommentInvalid             RejectReason = "CLIENT_ORDER_COMMENT_INVALID"
+	RejectClientTradeIDInvalid                  RejectReason = "CLIENT_TRADE_ID_INVALID"
+	RejectClientTradeIDAlreadyExists            RejectReason = "CLIENT_TRADE_ID_ALREADY_EXISTS"
+	RejectMarketHalted                          RejectReason = "MARKET_HALTED"
+	RejectTakeProfitOnFillPriceMissing          RejectReason = "TAKE_PROFIT_ON_FILL_PRICE_MISSING"
+	RejectTakeProfitOnFillPriceInvalid          RejectReason = "TAKE_PROFIT_ON_FILL_PRICE_INVALID"
+	RejectStopLossOnFillPriceMissing            RejectReason = "STOP_LOSS_ON_FILL_PRICE_MISSING"
+	RejectStopLossOnFillPriceInvalid            RejectReason = "STOP_LOSS_ON_FILL_PRICE_INVALID"
+	RejectStopLossOnFillRequired                RejectReason = "STOP_LOSS_ON_FILL_REQUIRED_FOR_PENDING_ORDER"
+	RejectGuaranteedStopLossOnFillNotAllowed    RejectReason = "STOP_LOSS_ON_FILL_GUARANTEED_NOT_ALLOWED"
+	RejectTrailingStopLossOnFillDistanceMissing RejectReason = "TRAILING_STOP_LOSS_ON_FILL_PRICE_DISTANCE_MISSING"
+	RejectTrailingStopLossOnFillDistanceMinimum RejectReason = "TRAILING_STOP_LOSS_ON_FILL_PRICE_DISTANCE_MINIMUM_NOT_MET"
+	RejectTrailingStopLossOnFillDistanceMaximum RejectReason = "TRAILING_STOP_LOSS_ON_FILL_PRICE_DISTANCE_MAXIMUM_EXCEEDED"
+	RejectCloseoutPositionDoesntExist           RejectReason = "CLOSEOUT_POSITION_DOESNT_EXIST"
+	RejectCloseoutPositionReject                RejectReason = "CLOSEOUT_POSITION_REJECT"
+	RejectCloseoutPositionUnitsExceedSize       RejectReason = "CLOSEOUT_POSITION_UNITS_EXCEED_POSITION_SIZE"
+	RejectCloseTradeUnitsExceedTradeSize        RejectReason = "CLOSE_TRADE_UNITS_EXCEED_TRADE_SIZE"
+	RejectFIFOViolationSafeguardViolation       RejectReason = "FIFO_VIOLATION_SAFEGUARD_VIOLATION"
+	RejectLosingTakeProfit                      RejectReason = "LOSING_TAKE_PROFIT"
+	RejectInsufficientFunds                     RejectReason = "INSUFFICIENT_FUNDS"
+	RejectClientExtensionsDataMissing           RejectReason = "CLIENT_EXTENSIONS_DATA_MISSING"
+	RejectReplacingOrderInvalid                 RejectReason = "REPLACING_ORDER_INVALID"
+)
+
+// RejectTransaction describes the transaction created when the api rejects a request,
+// e.g. an orderRejectTransaction
+type RejectTransaction struct {
+	ID            string       `json:"id"`
+	Time          time.Time    `json:"time"`
+	UserID        int          `json:"userID"`
+	AccountID     string       `json:"accountID"`
+	BatchID       string       `json:"batchID"`
+	RequestID     string       `json:"requestID"`
+	Type          string       `json:"type"`
+	Instrument    string       `json:"instrument,omitempty"`
+	Units         Decimal      `json:"units,omitempty"`
+	Price         Decimal      `json:"price,omitempty"`
+	OrderID       string       `json:"orderID,omitempty"`
+	ClientOrderID string       `json:"clientOrderID,omitempty"`
+	TradeID       string       `json:"tradeID,omitempty"`
+	Reason        string       `json:"reason,omitempty"`
+	RejectReason  RejectReason `json:"rejectReason"`
+}
+
 func newAPIError(request *http.Request, response *http.Response) APIError {
 	defer response.Body.Close()
 
 	msg := struct {
-		ErrorMessage string
-		RejectReason string
+		ErrorCode             string
+		ErrorMessage          string
+		RejectReason          RejectReason
+		RelatedTransactionIDs []string
+		LastTransactionID     string
 	}{}
 
 	apiErr := APIError{
-		Response: response,
-		Request:  request,
+		Response:   response,
+		Request:    request,
+		StatusCode: response.StatusCode,
 	}
 
 	b, _ := ioutil.ReadAll(response.Body)
 	err := json.Unmarshal(b, &msg)
 	if err != nil {
 		apiErr.Message = string(b)
-	} else {
-		apiErr.Message = msg.RejectReason + msg.ErrorMessage
+		return apiErr
+	}
+
+	apiErr.ErrorCode = msg.ErrorCode
+	apiErr.ErrorMessage = msg.ErrorMessage
+	apiErr.RejectReason = msg.RejectReason
+	apiErr.RelatedTransactionIDs = msg.RelatedTransactionIDs
+	apiErr.LastTransactionID = msg.LastTransactionID
+	apiErr.RejectTransaction = findRejectTransaction(b)
+
+	if apiErr.RejectReason == "" && apiErr.RejectTransaction != nil {
+		apiErr.RejectReason = apiErr.RejectTransaction.RejectReason
 	}
+
+	apiErr.Message = string(msg.RejectReason) + msg.ErrorMessage
 	return apiErr
 }
 
+// findRejectTransaction returns the first *RejectTransaction field of the response body,
+// in alphabetical order of the field names
+func findRejectTransaction(body []byte) *RejectTransaction {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if strings.HasSuffix(name, "RejectTransaction") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rt := RejectTransaction{}
+		if err := json.Unmarshal(fields[name], &rt); err == nil {
+			return &rt
+		}
+	}
+	return nil
+}
+
 // APIError is returned when the Oanda server responds with an error
 //
 // Message is the returned error message from the server if possible to unmarshal,
 // otherwise it is simply the entire body of the response
+//
+// ErrorCode, ErrorMessage and RejectReason are the fields of the same name in the
+// response body. RejectReason falls back to the reason given by RejectTransaction,
+// which holds the transaction the api created to record a rejected request, if any.
+//
+// APIError can be matched against the sentinel errors of this package with errors.Is,
+// e.g. errors.Is(err, ErrInsufficientMargin).
 type APIError struct {
 	Request  *http.Request
 	Response *http.Response
 	Message  string
+
+	StatusCode            int
+	ErrorCode             string
+	ErrorMessage          string
+	RejectReason          RejectReason
+	RejectTransaction     *RejectTransaction
+	RelatedTransactionIDs []string
+	LastTransactionID     string
 }
 
 // APIError implements error
@@ -48,3 +208,29 @@ func (a APIError) Error() string {
 		a.Message,
 	)
 }
+
+// Is reports whether the error matches one of the sentinel errors of this package
+func (a APIError) Is(target error) bool {
+	reason := a.RejectReason
+	if reason == "" {
+		reason = RejectReason(a.ErrorCode)
+	}
+
+	switch target {
+	case ErrBadRequest:
+		return a.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return a.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return a.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return a.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return a.StatusCode == http.StatusTooManyRequests
+	case ErrInsufficientMargin:
+		return reason == RejectInsufficientMargin
+	case ErrMarketHalted:
+		return reason == RejectMarketHalted
+	}
+	return false
+}
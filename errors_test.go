@@ -0,0 +1,112 @@
+package goanda
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIErrorOrderReject(t *testing.T) {
+	defer logTestResult(t, "APIErrorOrderReject")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{
+			"orderRejectTransaction": {
+				"id": "6372",
+				"accountID": "test-account",
+				"type": "MARKET_ORDER_REJECT",
+				"instrument": "EUR_USD",
+				"units": "100000000",
+				"reason": "CLIENT_ORDER",
+				"rejectReason": "INSUFFICIENT_MARGIN"
+			},
+			"relatedTransactionIDs": ["6372"],
+			"lastTransactionID": "6372",
+			"errorCode": "INSUFFICIENT_MARGIN",
+			"errorMessage": "Insufficient margin to open the order"
+		}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	_, err := c.CreateOrder(OrderPayload{Order: OrderBody{Instrument: "EUR_USD", Units: "100000000", Type: "MARKET"}})
+
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", apiErr.StatusCode)
+	}
+	if apiErr.ErrorCode != "INSUFFICIENT_MARGIN" {
+		t.Errorf("Expected error code INSUFFICIENT_MARGIN, got %s", apiErr.ErrorCode)
+	}
+	if apiErr.RejectReason != RejectInsufficientMargin {
+		t.Errorf("Expected reject reason INSUFFICIENT_MARGIN, got %s", apiErr.RejectReason)
+	}
+	if apiErr.RejectTransaction == nil {
+		t.Fatal("Expected the reject transaction to be decoded")
+	}
+	if apiErr.RejectTransaction.ID != "6372" || apiErr.RejectTransaction.Type != "MARKET_ORDER_REJECT" {
+		t.Errorf("Unexpected reject transaction: %+v", apiErr.RejectTransaction)
+	}
+	if !apiErr.RejectTransaction.Units.Equal("100000000") {
+		t.Errorf("Expected reject transaction units of 100000000, got %s", apiErr.RejectTransaction.Units)
+	}
+	if len(apiErr.RelatedTransactionIDs) != 1 || apiErr.LastTransactionID != "6372" {
+		t.Errorf("Unexpected transaction IDs: %v, %s", apiErr.RelatedTransactionIDs, apiErr.LastTransactionID)
+	}
+
+	if !errors.Is(err, ErrInsufficientMargin) {
+		t.Error("Expected error to match ErrInsufficientMargin")
+	}
+	if !errors.Is(fmt.Errorf("placing order: %w", err), ErrBadRequest) {
+		t.Error("Expected wrapped error to match ErrBadRequest")
+	}
+	if errors.Is(err, ErrMarketHalted) || errors.Is(err, ErrNotFound) {
+		t.Error("Expected error not to match unrelated sentinels")
+	}
+}
+
+func TestAPIErrorStatusSentinels(t *testing.T) {
+	defer logTestResult(t, "APIErrorStatusSentinels")
+
+	tests := []struct {
+		status int
+		body   string
+		target error
+	}{
+		{http.StatusUnauthorized, `{"errorMessage":"Insufficient authorization to perform request."}`, ErrUnauthorized},
+		{http.StatusForbidden, `{"errorMessage":"Forbidden"}`, ErrForbidden},
+		{http.StatusNotFound, `{"errorCode":"NO_SUCH_TRADE","errorMessage":"The Trade specified does not exist"}`, ErrNotFound},
+		{http.StatusTooManyRequests, `Rate limit exceeded`, ErrRateLimited},
+		{http.StatusBadRequest, `{"rejectReason":"MARKET_HALTED","errorMessage":"Market halted"}`, ErrMarketHalted},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		c := &Connection{
+			hostname:  server.URL,
+			accountID: "test-account",
+			client:    *server.Client(),
+		}
+
+		_, err := c.GetTrade("1")
+		if !errors.Is(err, tt.target) {
+			t.Errorf("Expected status %d to match %v, got %v", tt.status, tt.target, err)
+		}
+		server.Close()
+	}
+}
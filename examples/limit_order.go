@@ -30,19 +30,14 @@ func placeLimitOrder() {
 		log.Fatalf("Error creating connection: %v", err)
 	}
 
-	order := goanda.OrderPayload{
-		Order: goanda.OrderBody{
-			Units:        100,
-			Instrument:   "EUR_USD",
-			TimeInForce:  "FOK",
-			Type:         "LIMIT",
-			PositionFill: "DEFAULT",
-			Price:        "1.25000",
-			StopLossOnFill: &goanda.OnFill{
-				TimeInForce: "GTC",
-				Price:       "1.24000",
-			},
-		},
+	order, err := goanda.NewLimitOrder("EUR_USD", "100", "1.25000").
+		WithStopLossOnFill(goanda.OnFill{
+			TimeInForce: goanda.TimeInForceGTC,
+			Price:       "1.24000",
+		}).
+		Build()
+	if err != nil {
+		log.Fatalf("Error building order: %v", err)
 	}
 
 	orderResult, err := oanda.CreateOrder(order)
@@ -30,15 +30,9 @@ func placeMarketOrder() {
 		log.Fatalf("Error creating connection: %v", err)
 	}
 
-	order := goanda.OrderPayload{
-		Order: goanda.OrderBody{
-			Units:        10000,
-			Instrument:   "EUR_USD",
-			TimeInForce:  "GTC",
-			Type:         "MARKET",
-			PositionFill: "DEFAULT",
-			Price:        "1.25000",
-		},
+	order, err := goanda.NewMarketOrder("EUR_USD", "10000").Build()
+	if err != nil {
+		log.Fatalf("Error building order: %v", err)
 	}
 
 	orderResult, err := oanda.CreateOrder(order)
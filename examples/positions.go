@@ -31,8 +31,8 @@ func positions() {
 	}
 
 	closePosition, err := oanda.ClosePosition("AUD_USD", goanda.ClosePositionPayload{
-		LongUnits:  "NONE",
-		ShortUnits: "ALL",
+		LongUnits:  goanda.CloseNone,
+		ShortUnits: goanda.CloseAll,
 	})
 	if err != nil {
 		log.Fatalf("Error closing position: %v", err)
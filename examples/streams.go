@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -37,21 +38,22 @@ func streams() {
 	var wg sync.WaitGroup
 	wg.Add(2)
 
-	// Create a channel to signal goroutines to stop
-	stop := make(chan struct{})
+	// Cancelling the context stops both streams
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// Start price streaming
 	go func() {
 		defer wg.Done()
 		instruments := []string{"EUR_USD", "USD_JPY", "GBP_USD"}
-		err := streaming.StreamPrices(instruments, func(response goanda.PricingStreamResponse) {
+		err := streaming.StreamPricesContext(ctx, instruments, func(response goanda.PricingStreamResponse) {
 			fmt.Printf("Price update: %s - %s - Bid: %s, Ask: %s\n",
 				response.Time,
 				response.Instrument,
 				response.Bids[0].Price,
 				response.Asks[0].Price)
 		})
-		if err != nil {
+		if err != nil && err != context.Canceled {
 			log.Printf("Error streaming prices: %v", err)
 		}
 	}()
@@ -59,13 +61,13 @@ func streams() {
 	// Start transaction streaming
 	go func() {
 		defer wg.Done()
-		err := streaming.StreamTransactions(func(response goanda.TransactionStreamResponse) {
+		err := streaming.StreamTransactionsContext(ctx, func(response goanda.TransactionStreamResponse) {
 			fmt.Printf("Transaction update: %s - Type: %s, ID: %s\n",
 				response.Time,
 				response.Type,
 				response.TransactionID)
 		})
-		if err != nil {
+		if err != nil && err != context.Canceled {
 			log.Printf("Error streaming transactions: %v", err)
 		}
 	}()
@@ -78,8 +80,8 @@ func streams() {
 	<-sigChan
 	fmt.Println("\nReceived interrupt signal. Shutting down...")
 
-	// Signal goroutines to stop
-	close(stop)
+	// Stop the streams
+	cancel()
 
 	// Wait for goroutines to finish
 	wg.Wait()
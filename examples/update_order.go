@@ -32,7 +32,7 @@ func updateOrder() {
 
 	order := goanda.OrderPayload{
 		Order: goanda.OrderBody{
-			Units:        1000,
+			Units:        "1000",
 			Instrument:   "EUR_USD",
 			TimeInForce:  "FOK",
 			Type:         "LIMIT",
@@ -2,15 +2,24 @@ package goanda
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	apiUserAgent = "v20-golang/0.0.1"
 	httpTimeout  = time.Second * 5
+
+	practiceURL       = "https://api-fxpractice.oanda.com/v3"
+	liveURL           = "https://api-fxtrade.oanda.com/v3"
+	practiceStreamURL = "https://stream-fxpractice.oanda.com/v3"
+	liveStreamURL     = "https://stream-fxtrade.oanda.com/v3"
 )
 
 // ConnectionConfig is used to configure new connections
@@ -19,29 +28,66 @@ const (
 //	UserAgent	= v20-golang/0.0.1
 //	Timeout		= 5 seconds
 //	Live		= False
+//
+// Timeout applies to every request made through the connection. Use the
+// ...Context variants of each method to cancel or set a deadline on a single call.
+//
+// Retry is optional; when nil failed requests are returned to the caller straight away.
+// RateLimit is optional; when nil requests are never throttled.
+//
+// BaseURL and StreamURL replace the Oanda REST and streaming urls chosen by Live,
+// e.g. to go through a proxy or talk to a mock server. Both include the /v3 prefix.
+// HTTPClient replaces the http.Client used for every request, and Transport replaces
+// the RoundTripper of whichever client is in use. A non zero Timeout is applied on top
+// of either.
+//
+// Lazy skips the CheckConnection call made by NewConnection, so a connection can be
+// created without reaching the api. Use HealthCheck to probe the api when convenient.
+//
+// ValidateOrders checks the units of every order against the instrument's limits before
+// it is sent, returning a *UnitsError instead of making a request the api would reject.
+// The account's instruments are fetched on first use and cached.
 type ConnectionConfig struct {
-	UserAgent string
-	Timeout   time.Duration
-	Live      bool
+	UserAgent      string
+	Timeout        time.Duration
+	Live           bool
+	Retry          *RetryPolicy
+	RateLimit      *RateLimitConfig
+	BaseURL        string
+	StreamURL      string
+	HTTPClient     *http.Client
+	Transport      http.RoundTripper
+	Lazy           bool
+	ValidateOrders bool
 }
 
 // Connection describes a connection to the Oanda v20 API
 // It is thread safe
 type Connection struct {
 	hostname   string
+	streamURL  string
 	accountID  string
 	authHeader string
 	userAgent  string
 	client     http.Client
+	retry      *RetryPolicy
+	limiter    *rateLimiter
+
+	validateOrders bool
+
+	mu           sync.RWMutex
+	interceptors []Interceptor
+	instruments  map[string]Instrument
 }
 
 // NewConnection creates a new connection
-// This function calls Connection.CheckConnection(), returning any errors
+// This function calls Connection.CheckConnection(), returning any errors, unless config.Lazy is set
 // Supplying a config is optional, with sane defaults (paper trading) being used otherwise.
 func NewConnection(accountID string, token string, config *ConnectionConfig) (*Connection, error) {
 	// Make new connection with defaults
 	nc := &Connection{
-		hostname:   "https://api-fxpractice.oanda.com/v3",
+		hostname:   practiceURL,
+		streamURL:  practiceStreamURL,
 		accountID:  accountID,
 		authHeader: "Bearer " + token,
 		userAgent:  apiUserAgent,
@@ -53,61 +99,148 @@ func NewConnection(accountID string, token string, config *ConnectionConfig) (*C
 	// Overwrite things if we've been given configuration for them
 	if config != nil {
 		if config.Live {
-			nc.hostname = "https://api-fxtrade.oanda.com/v3"
+			nc.hostname = liveURL
+			nc.streamURL = liveStreamURL
+		}
+
+		if config.BaseURL != "" {
+			nc.hostname = strings.TrimSuffix(config.BaseURL, "/")
+		}
+
+		if config.StreamURL != "" {
+			nc.streamURL = strings.TrimSuffix(config.StreamURL, "/")
+		}
+
+		if config.HTTPClient != nil {
+			nc.client = *config.HTTPClient
+		}
+
+		if config.Transport != nil {
+			nc.client.Transport = config.Transport
 		}
 
 		if config.Timeout != 0 {
-			nc.client = http.Client{
-				Timeout: config.Timeout,
-			}
+			nc.client.Timeout = config.Timeout
 		}
 
 		if config.UserAgent != "" {
 			nc.userAgent = config.UserAgent
 		}
+
+		if config.Retry != nil {
+			retry := *config.Retry
+			nc.retry = &retry
+		}
+
+		if config.RateLimit != nil {
+			nc.limiter = newRateLimiter(*config.RateLimit)
+		}
+
+		nc.validateOrders = config.ValidateOrders
+
+		if config.Lazy {
+			return nc, nil
+		}
 	}
 
 	return nc, nc.CheckConnection()
 }
 
+// RateLimitStats returns the time requests have spent waiting on the rate limiter
+// The stats are always zero if the connection was not configured with a RateLimit.
+func (c *Connection) RateLimitStats() RateLimiterStats {
+	if c.limiter == nil {
+		return RateLimiterStats{}
+	}
+	return c.limiter.snapshot()
+}
+
 // CheckConnection performs a request, returning any errors encountered
 func (c *Connection) CheckConnection() error {
-	_, err := c.Get("/accounts/" + c.accountID)
+	return c.CheckConnectionContext(context.Background())
+}
+
+// CheckConnectionContext is CheckConnection bound to the given context
+func (c *Connection) CheckConnectionContext(ctx context.Context) error {
+	_, err := c.GetContext(ctx, "/accounts/"+c.accountID)
 	return err
 }
 
-// Get performs a generic http get on the api
-func (c *Connection) Get(endpoint string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodGet, c.hostname+endpoint, nil)
+// Health describes the result of a health check
+//
+// ServerTime is taken from the Date header of the response, and is zero if the api didn't send one.
+type Health struct {
+	Latency           time.Duration
+	ServerTime        time.Time
+	LastTransactionID string
+}
+
+// HealthCheck fetches the account summary, reporting how long the api took to respond
+func (c *Connection) HealthCheck() (Health, error) {
+	return c.HealthCheckContext(context.Background())
+}
+
+// HealthCheckContext is HealthCheck bound to the given context
+func (c *Connection) HealthCheckContext(ctx context.Context) (Health, error) {
+	h := Health{}
+
+	start := time.Now()
+	body, header, err := c.makeRequest(ctx, http.MethodGet, "/accounts/"+c.accountID+"/summary", nil, false)
+	h.Latency = time.Since(start)
 	if err != nil {
-		return nil, err
+		return h, err
+	}
+
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		h.ServerTime = date
+	}
+
+	var summary struct {
+		LastTransactionID string `json:"lastTransactionID"`
 	}
+	err = json.Unmarshal(body, &summary)
+	h.LastTransactionID = summary.LastTransactionID
+	return h, err
+}
+
+// Get performs a generic http get on the api
+func (c *Connection) Get(endpoint string) ([]byte, error) {
+	return c.GetContext(context.Background(), endpoint)
+}
 
-	return c.makeRequest(endpoint, c.client, req)
+// GetContext performs a generic http get on the api, bound to the given context
+func (c *Connection) GetContext(ctx context.Context, endpoint string) ([]byte, error) {
+	body, _, err := c.makeRequest(ctx, http.MethodGet, endpoint, nil, true)
+	return body, err
 }
 
 // Post performs a generic http post on the api
 func (c *Connection) Post(endpoint string, data []byte) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodPost, c.hostname+endpoint, bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
+	return c.PostContext(context.Background(), endpoint, data)
+}
 
-	return c.makeRequest(endpoint, c.client, req)
+// PostContext performs a generic http post on the api, bound to the given context
+// Posts are never retried, as the api may have acted on a request whose response was lost
+func (c *Connection) PostContext(ctx context.Context, endpoint string, data []byte) ([]byte, error) {
+	body, _, err := c.makeRequest(ctx, http.MethodPost, endpoint, data, false)
+	return body, err
 }
 
 // Put performs a generic http put on the api
 func (c *Connection) Put(endpoint string, data []byte) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodPut, c.hostname+endpoint, bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
+	return c.PutContext(context.Background(), endpoint, data)
+}
 
-	return c.makeRequest(endpoint, c.client, req)
+// PutContext performs a generic http put on the api, bound to the given context
+// Puts are retried, so endpoints that act on a number of units, such as partially closing
+// a trade or position, must not be called through PutContext with a retry policy set
+func (c *Connection) PutContext(ctx context.Context, endpoint string, data []byte) ([]byte, error) {
+	body, _, err := c.makeRequest(ctx, http.MethodPut, endpoint, data, true)
+	return body, err
 }
 
-func (c *Connection) getAndUnmarshal(endpoint string, receive interface{}) error {
-	response, err := c.Get(endpoint)
+func (c *Connection) getAndUnmarshal(ctx context.Context, endpoint string, receive interface{}) error {
+	response, err := c.GetContext(ctx, endpoint)
 	if err != nil {
 		return err
 	}
@@ -115,13 +248,16 @@ func (c *Connection) getAndUnmarshal(endpoint string, receive interface{}) error
 	return json.Unmarshal(response, receive)
 }
 
-func (c *Connection) postAndUnmarshal(endpoint string, send interface{}, receive interface{}) error {
+// postAndUnmarshal posts send to the endpoint. idempotent should only be set when the
+// request carries a client supplied key that stops the api acting on it twice, which
+// allows it to be retried.
+func (c *Connection) postAndUnmarshal(ctx context.Context, endpoint string, send interface{}, receive interface{}, idempotent bool) error {
 	data, err := json.Marshal(send)
 	if err != nil {
 		return err
 	}
 
-	response, err := c.Post(endpoint, data)
+	response, _, err := c.makeRequest(ctx, http.MethodPost, endpoint, data, idempotent)
 	if err != nil {
 		return err
 	}
@@ -129,13 +265,16 @@ func (c *Connection) postAndUnmarshal(endpoint string, send interface{}, receive
 	return json.Unmarshal(response, receive)
 }
 
-func (c *Connection) putAndUnmarshal(endpoint string, send interface{}, receive interface{}) error {
+// putAndUnmarshal puts send to the endpoint. idempotent should be unset when repeating the
+// request would act twice, as closing some units of a trade or position does, so that it
+// isn't retried after a response is lost.
+func (c *Connection) putAndUnmarshal(ctx context.Context, endpoint string, send interface{}, receive interface{}, idempotent bool) error {
 	data, err := json.Marshal(send)
 	if err != nil {
 		return err
 	}
 
-	response, err := c.Put(endpoint, data)
+	response, _, err := c.makeRequest(ctx, http.MethodPut, endpoint, data, idempotent)
 	if err != nil {
 		return err
 	}
@@ -143,24 +282,59 @@ func (c *Connection) putAndUnmarshal(endpoint string, send interface{}, receive
 	return json.Unmarshal(response, receive)
 }
 
-func (c *Connection) makeRequest(endpoint string, client http.Client, req *http.Request) ([]byte, error) {
+func (c *Connection) makeRequest(ctx context.Context, method string, endpoint string, data []byte, idempotent bool) ([]byte, http.Header, error) {
+	attempts := 1
+	if idempotent && c.retry != nil {
+		attempts = c.retry.maxAttempts()
+	}
+
+	for attempt := 1; ; attempt++ {
+		body, header, err := c.doRequest(ctx, method, endpoint, data)
+		if err == nil || attempt >= attempts || !c.retry.shouldRetry(ctx, err) {
+			return body, header, err
+		}
+
+		if waitErr := c.retry.wait(ctx, attempt, err); waitErr != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (c *Connection) doRequest(ctx context.Context, method string, endpoint string, data []byte) ([]byte, http.Header, error) {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx, endpoint); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.hostname+endpoint, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Authorization", c.authHeader)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := client.Do(req)
+	res, err := c.send(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if res.StatusCode >= 400 {
-		return nil, newAPIError(req, res)
-	}
-
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+		return nil, nil, newAPIError(req, &http.Response{
+			Status:     fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       io.NopCloser(bytes.NewReader(res.Body)),
+			Request:    req,
+		})
 	}
 
-	return body, nil
+	return res.Body, res.Header, nil
 }
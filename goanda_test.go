@@ -1,6 +1,13 @@
 package goanda
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
 
 func logTestResult(t *testing.T, name string) {
     if t.Failed() {
@@ -8,4 +15,164 @@ func logTestResult(t *testing.T, name string) {
     } else {
         t.Logf("\n✅ Test passed: %s", name)
 	}
-}
\ No newline at end of file
+}
+
+func TestGetContextCancelled(t *testing.T) {
+	defer logTestResult(t, "GetContextCancelled")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetOpenTradesContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected request to return promptly, took %v", elapsed)
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewConnectionCustomURLs(t *testing.T) {
+	defer logTestResult(t, "NewConnectionCustomURLs")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/accounts/test-account" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Unexpected Authorization header: %s", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"account":{"id":"test-account"},"lastTransactionID":"1"}`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	c, err := NewConnection("test-account", "test-token", &ConnectionConfig{
+		BaseURL:   server.URL + "/v3/",
+		StreamURL: "http://localhost:9999/v3",
+		Live:      true,
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if transport.requests != 1 {
+		t.Errorf("Expected the custom transport to be used once, got %d", transport.requests)
+	}
+	if c.hostname != server.URL+"/v3" {
+		t.Errorf("Expected hostname to be %s/v3, got %s", server.URL, c.hostname)
+	}
+	if c.client.Timeout != httpTimeout {
+		t.Errorf("Expected default timeout to be kept, got %v", c.client.Timeout)
+	}
+
+	sc := c.NewStreamingConnection()
+	if sc.streamURL != "http://localhost:9999/v3" {
+		t.Errorf("Expected streamURL to be http://localhost:9999/v3, got %s", sc.streamURL)
+	}
+}
+
+func TestNewConnectionCustomClient(t *testing.T) {
+	defer logTestResult(t, "NewConnectionCustomClient")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	client.Timeout = time.Minute
+
+	c, err := NewConnection("test-account", "test-token", &ConnectionConfig{
+		BaseURL:    server.URL,
+		HTTPClient: client,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.client.Timeout != time.Minute {
+		t.Errorf("Expected the client's timeout to be kept, got %v", c.client.Timeout)
+	}
+	if c.streamURL != practiceStreamURL {
+		t.Errorf("Expected streamURL to default to %s, got %s", practiceStreamURL, c.streamURL)
+	}
+}
+
+func TestNewConnectionLazy(t *testing.T) {
+	defer logTestResult(t, "NewConnectionLazy")
+
+	c, err := NewConnection("test-account", "test-token", &ConnectionConfig{
+		BaseURL: "http://127.0.0.1:1",
+		Lazy:    true,
+	})
+	if err != nil {
+		t.Fatalf("Expected a lazy connection not to make a request, got %v", err)
+	}
+
+	if _, err := c.HealthCheck(); err == nil {
+		t.Error("Expected the health check against an unreachable api to fail")
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	defer logTestResult(t, "HealthCheck")
+
+	serverTime := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/test-account/summary" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		w.Write([]byte(`{"account":{"id":"test-account"},"lastTransactionID":"1234"}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	h, err := c.HealthCheck()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !h.ServerTime.Equal(serverTime) {
+		t.Errorf("Expected server time %v, got %v", serverTime, h.ServerTime)
+	}
+	if h.LastTransactionID != "1234" {
+		t.Errorf("Expected LastTransactionID to be 1234, got %s", h.LastTransactionID)
+	}
+	if h.Latency <= 0 {
+		t.Errorf("Expected a positive latency, got %v", h.Latency)
+	}
+}
@@ -3,6 +3,7 @@ package goanda
 // Supporting OANDA docs - http://developer.oanda.com/rest-live-v20/instrument-ep/
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"time"
@@ -79,10 +80,10 @@ var candlestickGranularity = map[Granularity]string{
 }
 
 type Candle struct {
-	Open  float64 `json:"o,string"`
-	Close float64 `json:"c,string"`
-	Low   float64 `json:"l,string"`
-	High  float64 `json:"h,string"`
+	Open  Decimal `json:"o"`
+	Close Decimal `json:"c"`
+	Low   Decimal `json:"l"`
+	High  Decimal `json:"h"`
 }
 
 type Candles struct {
@@ -95,16 +96,16 @@ type Candles struct {
 type BidAskCandles struct {
 	Candles []struct {
 		Ask struct {
-			C float64 `json:"c,string"`
-			H float64 `json:"h,string"`
-			L float64 `json:"l,string"`
-			O float64 `json:"o,string"`
+			C Decimal `json:"c"`
+			H Decimal `json:"h"`
+			L Decimal `json:"l"`
+			O Decimal `json:"o"`
 		} `json:"ask"`
 		Bid struct {
-			C float64 `json:"c,string"`
-			H float64 `json:"h,string"`
-			L float64 `json:"l,string"`
-			O float64 `json:"o,string"`
+			C Decimal `json:"c"`
+			H Decimal `json:"h"`
+			L Decimal `json:"l"`
+			O Decimal `json:"o"`
 		} `json:"bid"`
 		Complete bool      `json:"complete"`
 		Time     time.Time `json:"time"`
@@ -119,16 +120,16 @@ type InstrumentHistory struct {
 }
 
 type Bucket struct {
-	Price             string `json:"price"`
-	LongCountPercent  string `json:"longCountPercent"`
-	ShortCountPercent string `json:"shortCountPercent"`
+	Price             Decimal `json:"price"`
+	LongCountPercent  Decimal `json:"longCountPercent"`
+	ShortCountPercent Decimal `json:"shortCountPercent"`
 }
 
 type BrokerBook struct {
 	Instrument  string    `json:"instrument"`
 	Time        time.Time `json:"time"`
-	Price       string    `json:"price"`
-	BucketWidth string    `json:"bucketWidth"`
+	Price       Decimal   `json:"price"`
+	BucketWidth Decimal   `json:"bucketWidth"`
 	Buckets     []Bucket  `json:"buckets"`
 }
 
@@ -138,46 +139,52 @@ type InstrumentPricing struct {
 		Type string    `json:"type"`
 		Time time.Time `json:"time"`
 		Bids []struct {
-			Price     float64 `json:"price,string"`
+			Price     Decimal `json:"price"`
 			Liquidity int     `json:"liquidity"`
 		} `json:"bids"`
 		Asks []struct {
-			Price     float64 `json:"price,string"`
+			Price     Decimal `json:"price"`
 			Liquidity int     `json:"liquidity"`
 		} `json:"asks"`
-		CloseoutBid    float64 `json:"closeoutBid,string"`
-		CloseoutAsk    float64 `json:"closeoutAsk,string"`
+		CloseoutBid    Decimal `json:"closeoutBid"`
+		CloseoutAsk    Decimal `json:"closeoutAsk"`
 		Status         string  `json:"status"`
 		Tradeable      bool    `json:"tradeable"`
 		UnitsAvailable struct {
 			Default struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"default"`
 			OpenOnly struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"openOnly"`
 			ReduceFirst struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"reduceFirst"`
 			ReduceOnly struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"reduceOnly"`
 		} `json:"unitsAvailable"`
 		QuoteHomeConversionFactors struct {
-			PositiveUnits string `json:"positiveUnits"`
-			NegativeUnits string `json:"negativeUnits"`
+			PositiveUnits Decimal `json:"positiveUnits"`
+			NegativeUnits Decimal `json:"negativeUnits"`
 		} `json:"quoteHomeConversionFactors"`
 		Instrument string `json:"instrument"`
 	} `json:"prices"`
 }
 
 func (c *Connection) GetCandles(instrument string, count int, g Granularity) (InstrumentHistory, error) {
+	return c.GetCandlesContext(context.Background(), instrument, count, g)
+}
+
+// GetCandlesContext is GetCandles bound to the given context
+func (c *Connection) GetCandlesContext(ctx context.Context, instrument string, count int, g Granularity) (InstrumentHistory, error) {
 	ca := InstrumentHistory{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/instruments/"+
 			instrument+
 			"/candles?count="+
@@ -190,8 +197,14 @@ func (c *Connection) GetCandles(instrument string, count int, g Granularity) (In
 }
 
 func (c *Connection) GetTimeToCandles(instrument string, count int, g Granularity, to time.Time) (InstrumentHistory, error) {
+	return c.GetTimeToCandlesContext(context.Background(), instrument, count, g, to)
+}
+
+// GetTimeToCandlesContext is GetTimeToCandles bound to the given context
+func (c *Connection) GetTimeToCandlesContext(ctx context.Context, instrument string, count int, g Granularity, to time.Time) (InstrumentHistory, error) {
 	ih := InstrumentHistory{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/instruments/"+
 			instrument+
 			"/candles?count="+
@@ -205,8 +218,14 @@ func (c *Connection) GetTimeToCandles(instrument string, count int, g Granularit
 	return ih, err
 }
 func (c *Connection) GetTimeFromCandles(instrument string, count int, g Granularity, from time.Time) (InstrumentHistory, error) {
+	return c.GetTimeFromCandlesContext(context.Background(), instrument, count, g, from)
+}
+
+// GetTimeFromCandlesContext is GetTimeFromCandles bound to the given context
+func (c *Connection) GetTimeFromCandlesContext(ctx context.Context, instrument string, count int, g Granularity, from time.Time) (InstrumentHistory, error) {
 	ih := InstrumentHistory{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/instruments/"+
 			instrument+
 			"/candles?count="+
@@ -221,8 +240,14 @@ func (c *Connection) GetTimeFromCandles(instrument string, count int, g Granular
 }
 
 func (c *Connection) GetBidAskCandles(instrument string, count string, g Granularity) (BidAskCandles, error) {
+	return c.GetBidAskCandlesContext(context.Background(), instrument, count, g)
+}
+
+// GetBidAskCandlesContext is GetBidAskCandles bound to the given context
+func (c *Connection) GetBidAskCandlesContext(ctx context.Context, instrument string, count string, g Granularity) (BidAskCandles, error) {
 	ca := BidAskCandles{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/instruments/"+
 			instrument+
 			"/candles?count="+
@@ -236,8 +261,14 @@ func (c *Connection) GetBidAskCandles(instrument string, count string, g Granula
 }
 
 func (c *Connection) OrderBook(instrument string) (BrokerBook, error) {
+	return c.OrderBookContext(context.Background(), instrument)
+}
+
+// OrderBookContext is OrderBook bound to the given context
+func (c *Connection) OrderBookContext(ctx context.Context, instrument string) (BrokerBook, error) {
 	bb := BrokerBook{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/instruments/"+
 			instrument+
 			"/orderBook",
@@ -247,8 +278,14 @@ func (c *Connection) OrderBook(instrument string) (BrokerBook, error) {
 }
 
 func (c *Connection) PositionBook(instrument string) (BrokerBook, error) {
+	return c.PositionBookContext(context.Background(), instrument)
+}
+
+// PositionBookContext is PositionBook bound to the given context
+func (c *Connection) PositionBookContext(ctx context.Context, instrument string) (BrokerBook, error) {
 	bb := BrokerBook{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/instruments/"+
 			instrument+
 			"/positionBook",
@@ -258,8 +295,14 @@ func (c *Connection) PositionBook(instrument string) (BrokerBook, error) {
 }
 
 func (c *Connection) GetInstrumentPrice(instrument string) (InstrumentPricing, error) {
+	return c.GetInstrumentPriceContext(context.Background(), instrument)
+}
+
+// GetInstrumentPriceContext is GetInstrumentPrice bound to the given context
+func (c *Connection) GetInstrumentPriceContext(ctx context.Context, instrument string) (InstrumentPricing, error) {
 	ip := InstrumentPricing{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/pricing?instruments="+
@@ -111,10 +111,10 @@ func TestGetCandles(t *testing.T) {
 					Volume:   100,
 					Time:     time.Now(),
 					Mid: Candle{
-						Open:  1.1000,
-						High:  1.1010,
-						Low:   1.0990,
-						Close: 1.1005,
+						Open:  "1.1000",
+						High:  "1.1010",
+						Low:   "1.0990",
+						Close: "1.1005",
 					},
 				},
 			},
@@ -154,8 +154,8 @@ func TestGetCandles(t *testing.T) {
 		t.Errorf("Expected Volume to be 100, got %d", candle.Volume)
 	}
 
-	if candle.Mid.Open != 1.1000 {
-		t.Errorf("Expected Open to be 1.1000, got %f", candle.Mid.Open)
+	if candle.Mid.Open != "1.1000" {
+		t.Errorf("Expected Open to be 1.1000, got %s", candle.Mid.Open)
 	}
 }
 
@@ -193,10 +193,10 @@ func TestGetTimeToCandles(t *testing.T) {
 					Volume:   100,
 					Time:     time.Now(),
 					Mid: Candle{
-						Open:  1.1000,
-						High:  1.1010,
-						Low:   1.0990,
-						Close: 1.1005,
+						Open:  "1.1000",
+						High:  "1.1010",
+						Low:   "1.0990",
+						Close: "1.1005",
 					},
 				},
 			},
@@ -263,10 +263,10 @@ func TestGetTimeFromCandles(t *testing.T) {
 					Volume:   100,
 					Time:     time.Now(),
 					Mid: Candle{
-						Open:  1.1000,
-						High:  1.1010,
-						Low:   1.0990,
-						Close: 1.1005,
+						Open:  "1.1000",
+						High:  "1.1010",
+						Low:   "1.0990",
+						Close: "1.1005",
 					},
 				},
 			},
@@ -327,16 +327,16 @@ func TestGetBidAskCandles(t *testing.T) {
 		response := BidAskCandles{
 			Candles: []struct {
 				Ask struct {
-					C float64 `json:"c,string"`
-					H float64 `json:"h,string"`
-					L float64 `json:"l,string"`
-					O float64 `json:"o,string"`
+					C Decimal `json:"c"`
+					H Decimal `json:"h"`
+					L Decimal `json:"l"`
+					O Decimal `json:"o"`
 				} `json:"ask"`
 				Bid struct {
-					C float64 `json:"c,string"`
-					H float64 `json:"h,string"`
-					L float64 `json:"l,string"`
-					O float64 `json:"o,string"`
+					C Decimal `json:"c"`
+					H Decimal `json:"h"`
+					L Decimal `json:"l"`
+					O Decimal `json:"o"`
 				} `json:"bid"`
 				Complete bool      `json:"complete"`
 				Time     time.Time `json:"time"`
@@ -344,26 +344,26 @@ func TestGetBidAskCandles(t *testing.T) {
 			}{
 				{
 					Ask: struct {
-						C float64 `json:"c,string"`
-						H float64 `json:"h,string"`
-						L float64 `json:"l,string"`
-						O float64 `json:"o,string"`
+						C Decimal `json:"c"`
+						H Decimal `json:"h"`
+						L Decimal `json:"l"`
+						O Decimal `json:"o"`
 					}{
-						O: 1.1000,
-						H: 1.1010,
-						L: 1.0990,
-						C: 1.1005,
+						O: "1.1000",
+						H: "1.1010",
+						L: "1.0990",
+						C: "1.1005",
 					},
 					Bid: struct {
-						C float64 `json:"c,string"`
-						H float64 `json:"h,string"`
-						L float64 `json:"l,string"`
-						O float64 `json:"o,string"`
+						C Decimal `json:"c"`
+						H Decimal `json:"h"`
+						L Decimal `json:"l"`
+						O Decimal `json:"o"`
 					}{
-						O: 1.0998,
-						H: 1.1008,
-						L: 1.0988,
-						C: 1.1003,
+						O: "1.0998",
+						H: "1.1008",
+						L: "1.0988",
+						C: "1.1003",
 					},
 					Complete: true,
 					Time:     time.Now(),
@@ -398,12 +398,12 @@ func TestGetBidAskCandles(t *testing.T) {
 		t.Errorf("Expected Volume to be 100, got %d", candle.Volume)
 	}
 
-	if candle.Ask.O != 1.1000 {
-		t.Errorf("Expected Ask Open to be 1.1000, got %f", candle.Ask.O)
+	if candle.Ask.O != "1.1000" {
+		t.Errorf("Expected Ask Open to be 1.1000, got %s", candle.Ask.O)
 	}
 
-	if candle.Bid.O != 1.0998 {
-		t.Errorf("Expected Bid Open to be 1.0998, got %f", candle.Bid.O)
+	if candle.Bid.O != "1.0998" {
+		t.Errorf("Expected Bid Open to be 1.0998, got %s", candle.Bid.O)
 	}
 }
 
@@ -424,17 +424,17 @@ func TestOrderBook(t *testing.T) {
 			BucketWidth: "0.0001",
 			Buckets: []Bucket{
 				{
-					Price:      "1.0999",
+					Price:             "1.0999",
 					LongCountPercent:  "40",
 					ShortCountPercent: "60",
 				},
 				{
-					Price:      "1.1000",
+					Price:             "1.1000",
 					LongCountPercent:  "50",
 					ShortCountPercent: "50",
 				},
 				{
-					Price:      "1.1001",
+					Price:             "1.1001",
 					LongCountPercent:  "60",
 					ShortCountPercent: "40",
 				},
@@ -481,4 +481,4 @@ func TestOrderBook(t *testing.T) {
 	if book.Buckets[0].ShortCountPercent != "60" {
 		t.Errorf("Expected first bucket ShortCountPercent to be 60, got %s", book.Buckets[0].ShortCountPercent)
 	}
-}
\ No newline at end of file
+}
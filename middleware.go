@@ -0,0 +1,94 @@
+package goanda
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// Response is the api's response to a single request, as seen by interceptors
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Latency    time.Duration
+}
+
+// RequestID returns the id Oanda assigned to the request, useful when contacting support
+func (r *Response) RequestID() string {
+	return r.Header.Get("RequestID")
+}
+
+// RequestFunc sends a request to the api
+type RequestFunc func(req *http.Request) (*Response, error)
+
+// Interceptor wraps every request made through a Connection, e.g. to log, collect
+// metrics or trace requests.
+//
+// An interceptor is handed the outgoing request and must call next to send it,
+// returning what next returns. It may modify the request (such as adding headers or
+// replacing its context) before passing it on. Error responses from the api are
+// returned by next as a Response; only transport failures are returned as errors.
+// Retries are sent through the interceptors again.
+type Interceptor func(req *http.Request, next RequestFunc) (*Response, error)
+
+// Use registers interceptors on the connection. Interceptors run in the order they
+// were registered, the first being the outermost.
+func (c *Connection) Use(interceptors ...Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Copy, so requests already running keep the chain they started with
+	chain := make([]Interceptor, 0, len(c.interceptors)+len(interceptors))
+	chain = append(chain, c.interceptors...)
+	c.interceptors = append(chain, interceptors...)
+}
+
+// RedactHeader returns a copy of the header that is safe to log, with the
+// Authorization header masked
+func RedactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted.Get("Authorization") != "" {
+		redacted.Set("Authorization", "[REDACTED]")
+	}
+	return redacted
+}
+
+// send runs the request through the interceptors and then the http client
+func (c *Connection) send(req *http.Request) (*Response, error) {
+	c.mu.RLock()
+	interceptors := c.interceptors
+	c.mu.RUnlock()
+
+	next := c.roundTrip
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(req *http.Request) (*Response, error) {
+			return interceptor(req, inner)
+		}
+	}
+
+	return next(req)
+}
+
+func (c *Connection) roundTrip(req *http.Request) (*Response, error) {
+	start := time.Now()
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+		Latency:    time.Since(start),
+	}, nil
+}
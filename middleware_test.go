@@ -0,0 +1,92 @@
+package goanda
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	defer logTestResult(t, "Interceptors")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") != "00-trace-span-01" {
+			t.Errorf("Expected interceptor header to be sent, got %q", r.Header.Get("Traceparent"))
+		}
+		w.Header().Set("RequestID", "42")
+		if r.URL.Path == "/accounts/test-account/trades/999" {
+			http.Error(w, `{"errorMessage":"The trade does not exist"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(ReceivedTrades{LastTransactionID: "1000"})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:   server.URL,
+		accountID:  "test-account",
+		authHeader: "Bearer secret",
+		client:     *server.Client(),
+	}
+
+	var order []string
+	var seen []*Response
+	c.Use(
+		func(req *http.Request, next RequestFunc) (*Response, error) {
+			order = append(order, "outer")
+			res, err := next(req)
+			if err == nil {
+				seen = append(seen, res)
+			}
+			return res, err
+		},
+		func(req *http.Request, next RequestFunc) (*Response, error) {
+			order = append(order, "inner")
+			req.Header.Set("Traceparent", "00-trace-span-01")
+			return next(req)
+		},
+	)
+
+	if _, err := c.GetOpenTrades(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.GetTrade("999"); err == nil {
+		t.Fatal("Expected an error for a missing trade")
+	}
+
+	if len(order) != 4 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Expected interceptors to run outer first, got %v", order)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("Expected 2 responses to be seen, got %d", len(seen))
+	}
+	if seen[0].StatusCode != http.StatusOK || seen[1].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statuses 200 and 404, got %d and %d", seen[0].StatusCode, seen[1].StatusCode)
+	}
+	if seen[0].RequestID() != "42" {
+		t.Errorf("Expected RequestID to be 42, got %s", seen[0].RequestID())
+	}
+	if len(seen[0].Body) == 0 {
+		t.Error("Expected response body to be available")
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	defer logTestResult(t, "RedactHeader")
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("User-Agent", "goanda")
+
+	redacted := RedactHeader(header)
+	if redacted.Get("Authorization") != "[REDACTED]" {
+		t.Errorf("Expected Authorization to be redacted, got %s", redacted.Get("Authorization"))
+	}
+	if redacted.Get("User-Agent") != "goanda" {
+		t.Errorf("Expected User-Agent to be kept, got %s", redacted.Get("User-Agent"))
+	}
+	if header.Get("Authorization") != "Bearer secret" {
+		t.Error("Expected the original header to be left untouched")
+	}
+}
@@ -0,0 +1,336 @@
+package goanda
+
+// Supporting OANDA docs - http://developer.oanda.com/rest-live-v20/order-df/
+
+import (
+	"fmt"
+	"time"
+)
+
+// OrderType is the type of an order
+type OrderType string
+
+const (
+	OrderTypeMarket             OrderType = "MARKET"
+	OrderTypeLimit              OrderType = "LIMIT"
+	OrderTypeStop               OrderType = "STOP"
+	OrderTypeMarketIfTouched    OrderType = "MARKET_IF_TOUCHED"
+	OrderTypeTakeProfit         OrderType = "TAKE_PROFIT"
+	OrderTypeStopLoss           OrderType = "STOP_LOSS"
+	OrderTypeGuaranteedStopLoss OrderType = "GUARANTEED_STOP_LOSS"
+	OrderTypeTrailingStopLoss   OrderType = "TRAILING_STOP_LOSS"
+	OrderTypeFixedPrice         OrderType = "FIXED_PRICE"
+)
+
+// TimeInForce specifies how long an order remains pending before it is filled or cancelled
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC" // Good until cancelled
+	TimeInForceGTD TimeInForce = "GTD" // Good until the order's GTDTime
+	TimeInForceGFD TimeInForce = "GFD" // Good for the trading day
+	TimeInForceFOK TimeInForce = "FOK" // Filled entirely or cancelled
+	TimeInForceIOC TimeInForce = "IOC" // Filled as much as possible, the rest cancelled
+)
+
+// PositionFill specifies how an order's fill affects the account's positions
+type PositionFill string
+
+const (
+	PositionFillOpenOnly    PositionFill = "OPEN_ONLY"
+	PositionFillReduceFirst PositionFill = "REDUCE_FIRST"
+	PositionFillReduceOnly  PositionFill = "REDUCE_ONLY"
+	PositionFillDefault     PositionFill = "DEFAULT"
+)
+
+// TriggerCondition specifies which price component triggers a pending order
+type TriggerCondition string
+
+const (
+	TriggerConditionDefault TriggerCondition = "DEFAULT"
+	TriggerConditionInverse TriggerCondition = "INVERSE"
+	TriggerConditionBid     TriggerCondition = "BID"
+	TriggerConditionAsk     TriggerCondition = "ASK"
+	TriggerConditionMid     TriggerCondition = "MID"
+)
+
+// OrderError is returned when an order is missing a field its type requires, or has
+// fields that can't be combined
+type OrderError struct {
+	Type    OrderType
+	Field   string
+	Message string
+}
+
+// OrderError implements error
+func (e *OrderError) Error() string {
+	return fmt.Sprintf("goanda: invalid %s order: %s %s", e.Type, e.Field, e.Message)
+}
+
+// Validate checks that the order has the fields required by its type. It doesn't check
+// the order against the instrument, see Instrument.ValidateUnits for that.
+func (o OrderBody) Validate() error {
+	invalid := func(field, message string) error {
+		return &OrderError{Type: o.Type, Field: field, Message: message}
+	}
+
+	switch o.Type {
+	case OrderTypeMarket, OrderTypeLimit, OrderTypeStop, OrderTypeMarketIfTouched:
+		if o.Instrument == "" {
+			return invalid("instrument", "is required")
+		}
+		if !o.Units.Valid() {
+			return invalid("units", "are not a decimal number")
+		}
+		if o.Units.IsZero() {
+			return invalid("units", "are required")
+		}
+		if o.TradeID != "" {
+			return invalid("tradeID", "is only allowed on orders dependent on a trade")
+		}
+		if o.Type == OrderTypeMarket {
+			// The api fills market orders without a time in force as FOK
+			if o.TimeInForce != "" && o.TimeInForce != TimeInForceFOK && o.TimeInForce != TimeInForceIOC {
+				return invalid("timeInForce", "must be FOK or IOC")
+			}
+		} else if o.Price == "" {
+			return invalid("price", "is required")
+		}
+	case OrderTypeTakeProfit, OrderTypeStopLoss, OrderTypeGuaranteedStopLoss, OrderTypeTrailingStopLoss:
+		if o.TradeID == "" {
+			return invalid("tradeID", "is required")
+		}
+		if o.Units != "" {
+			return invalid("units", "are taken from the trade and must not be set")
+		}
+		switch o.Type {
+		case OrderTypeTakeProfit:
+			if o.Price == "" {
+				return invalid("price", "is required")
+			}
+		case OrderTypeTrailingStopLoss:
+			if o.Distance == "" {
+				return invalid("distance", "is required")
+			}
+		default:
+			if (o.Price == "") == (o.Distance == "") {
+				return invalid("price", "or distance is required, but not both")
+			}
+		}
+	case "":
+		return invalid("type", "is required")
+	default:
+		return invalid("type", "is not supported")
+	}
+
+	if o.TimeInForce == TimeInForceGTD && (o.GTDTime == nil || o.GTDTime.IsZero()) {
+		return invalid("gtdTime", "is required when timeInForce is GTD")
+	}
+
+	onFills := []struct {
+		field   string
+		onFill  *OnFill
+		orderOf OrderType
+	}{
+		{"takeProfitOnFill", o.TakeProfitOnFill, OrderTypeTakeProfit},
+		{"stopLossOnFill", o.StopLossOnFill, OrderTypeStopLoss},
+		{"guaranteedStopLossOnFill", o.GuaranteedStopLossOnFill, OrderTypeGuaranteedStopLoss},
+		{"trailingStopLossOnFill", o.TrailingStopLossOnFill, OrderTypeTrailingStopLoss},
+	}
+	for _, f := range onFills {
+		if f.onFill == nil {
+			continue
+		}
+		if err := f.onFill.validate(f.orderOf); err != nil {
+			return invalid(f.field, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validate checks the details of an order created when the order it is attached to fills
+func (f OnFill) validate(t OrderType) error {
+	switch t {
+	case OrderTypeTakeProfit:
+		if f.Price == "" {
+			return fmt.Errorf("price is required")
+		}
+	case OrderTypeTrailingStopLoss:
+		if f.Distance == "" {
+			return fmt.Errorf("distance is required")
+		}
+	default:
+		if (f.Price == "") == (f.Distance == "") {
+			return fmt.Errorf("price or distance is required, but not both")
+		}
+	}
+	if f.TimeInForce == TimeInForceGTD && f.GtdTime == "" {
+		return fmt.Errorf("gtdTime is required when timeInForce is GTD")
+	}
+	return nil
+}
+
+// OrderBuilder builds an OrderPayload for CreateOrder or UpdateOrder, checking that
+// the fields required by the order's type are set when Build is called
+type OrderBuilder struct {
+	body OrderBody
+}
+
+// NewMarketOrder starts a market order for the instrument, filled immediately or
+// cancelled. Units are negative for a sell.
+func NewMarketOrder(instrument string, units Decimal) *OrderBuilder {
+	return &OrderBuilder{body: OrderBody{
+		Type:         OrderTypeMarket,
+		Instrument:   instrument,
+		Units:        units,
+		TimeInForce:  TimeInForceFOK,
+		PositionFill: PositionFillDefault,
+	}}
+}
+
+// NewLimitOrder starts an order filled when the price reaches the given price or better
+func NewLimitOrder(instrument string, units Decimal, price Decimal) *OrderBuilder {
+	return newEntryOrder(OrderTypeLimit, instrument, units, price)
+}
+
+// NewStopOrder starts an order filled when the price reaches the given price or worse
+func NewStopOrder(instrument string, units Decimal, price Decimal) *OrderBuilder {
+	return newEntryOrder(OrderTypeStop, instrument, units, price)
+}
+
+// NewMarketIfTouchedOrder starts an order filled at market when the price touches the given price
+func NewMarketIfTouchedOrder(instrument string, units Decimal, price Decimal) *OrderBuilder {
+	return newEntryOrder(OrderTypeMarketIfTouched, instrument, units, price)
+}
+
+func newEntryOrder(t OrderType, instrument string, units Decimal, price Decimal) *OrderBuilder {
+	return &OrderBuilder{body: OrderBody{
+		Type:             t,
+		Instrument:       instrument,
+		Units:            units,
+		Price:            price,
+		TimeInForce:      TimeInForceGTC,
+		PositionFill:     PositionFillDefault,
+		TriggerCondition: TriggerConditionDefault,
+	}}
+}
+
+// NewTakeProfitOrder starts an order closing the trade when the price reaches the given price
+func NewTakeProfitOrder(tradeID string, price Decimal) *OrderBuilder {
+	return newDependentOrder(OrderTypeTakeProfit, tradeID, price)
+}
+
+// NewStopLossOrder starts an order closing the trade when the price reaches the given
+// price. Pass an empty price and use WithDistance to place it a distance from the trade's price.
+func NewStopLossOrder(tradeID string, price Decimal) *OrderBuilder {
+	return newDependentOrder(OrderTypeStopLoss, tradeID, price)
+}
+
+// NewGuaranteedStopLossOrder starts a stop loss order that is guaranteed to fill at its price
+func NewGuaranteedStopLossOrder(tradeID string, price Decimal) *OrderBuilder {
+	return newDependentOrder(OrderTypeGuaranteedStopLoss, tradeID, price)
+}
+
+// NewTrailingStopLossOrder starts an order closing the trade when the price moves the
+// given distance against it from its best price
+func NewTrailingStopLossOrder(tradeID string, distance Decimal) *OrderBuilder {
+	b := newDependentOrder(OrderTypeTrailingStopLoss, tradeID, "")
+	b.body.Distance = distance
+	return b
+}
+
+func newDependentOrder(t OrderType, tradeID string, price Decimal) *OrderBuilder {
+	return &OrderBuilder{body: OrderBody{
+		Type:             t,
+		TradeID:          tradeID,
+		Price:            price,
+		TimeInForce:      TimeInForceGTC,
+		TriggerCondition: TriggerConditionDefault,
+	}}
+}
+
+func (b *OrderBuilder) WithTimeInForce(tif TimeInForce) *OrderBuilder {
+	b.body.TimeInForce = tif
+	return b
+}
+
+// WithGTDTime sets when the order is cancelled, and its time in force to GTD
+func (b *OrderBuilder) WithGTDTime(t time.Time) *OrderBuilder {
+	b.body.TimeInForce = TimeInForceGTD
+	b.body.GTDTime = &t
+	return b
+}
+
+func (b *OrderBuilder) WithPositionFill(fill PositionFill) *OrderBuilder {
+	b.body.PositionFill = fill
+	return b
+}
+
+func (b *OrderBuilder) WithTriggerCondition(condition TriggerCondition) *OrderBuilder {
+	b.body.TriggerCondition = condition
+	return b
+}
+
+func (b *OrderBuilder) WithPrice(price Decimal) *OrderBuilder {
+	b.body.Price = price
+	return b
+}
+
+// WithPriceBound sets the worst price the order may be filled at
+func (b *OrderBuilder) WithPriceBound(price Decimal) *OrderBuilder {
+	b.body.PriceBound = price
+	return b
+}
+
+// WithDistance sets the distance from the trade's price for stop loss orders, or from
+// the best price for trailing stop loss orders
+func (b *OrderBuilder) WithDistance(distance Decimal) *OrderBuilder {
+	b.body.Distance = distance
+	return b
+}
+
+func (b *OrderBuilder) WithClientExtensions(extensions OrderExtensions) *OrderBuilder {
+	b.body.ClientExtensions = &extensions
+	return b
+}
+
+// WithTradeClientExtensions sets the client extensions of the trade opened when the order fills
+func (b *OrderBuilder) WithTradeClientExtensions(extensions OrderExtensions) *OrderBuilder {
+	b.body.TradeClientExtensions = &extensions
+	return b
+}
+
+// WithTakeProfitOnFill attaches a take profit order to the trade opened when the order fills
+func (b *OrderBuilder) WithTakeProfitOnFill(details OnFill) *OrderBuilder {
+	b.body.TakeProfitOnFill = &details
+	return b
+}
+
+// WithStopLossOnFill attaches a stop loss order to the trade opened when the order fills
+func (b *OrderBuilder) WithStopLossOnFill(details OnFill) *OrderBuilder {
+	b.body.StopLossOnFill = &details
+	return b
+}
+
+// WithGuaranteedStopLossOnFill attaches a guaranteed stop loss order to the trade opened
+// when the order fills
+func (b *OrderBuilder) WithGuaranteedStopLossOnFill(details OnFill) *OrderBuilder {
+	b.body.GuaranteedStopLossOnFill = &details
+	return b
+}
+
+// WithTrailingStopLossOnFill attaches a trailing stop loss order to the trade opened
+// when the order fills
+func (b *OrderBuilder) WithTrailingStopLossOnFill(details OnFill) *OrderBuilder {
+	b.body.TrailingStopLossOnFill = &details
+	return b
+}
+
+// Build returns the order's payload, or an *OrderError if a field its type requires is missing
+func (b *OrderBuilder) Build() (OrderPayload, error) {
+	if err := b.body.Validate(); err != nil {
+		return OrderPayload{}, err
+	}
+	return OrderPayload{Order: b.body}, nil
+}
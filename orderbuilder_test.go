@@ -0,0 +1,123 @@
+package goanda
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBuilders(t *testing.T) {
+	defer logTestResult(t, "OrderBuilders")
+
+	gtd := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		order *OrderBuilder
+		field string
+	}{
+		{"market", NewMarketOrder("EUR_USD", "-100"), ""},
+		{"market without units", NewMarketOrder("EUR_USD", ""), "units"},
+		{"market with invalid units", NewMarketOrder("EUR_USD", "10,000"), "units"},
+		{"market without time in force", NewMarketOrder("EUR_USD", "100").WithTimeInForce(""), ""},
+		{"market without instrument", NewMarketOrder("", "100"), "instrument"},
+		{"market GTC", NewMarketOrder("EUR_USD", "100").WithTimeInForce(TimeInForceGTC), "timeInForce"},
+		{"limit", NewLimitOrder("EUR_USD", "100", "1.10000"), ""},
+		{"limit without price", NewLimitOrder("EUR_USD", "100", ""), "price"},
+		{"limit GTD", NewLimitOrder("EUR_USD", "100", "1.1").WithGTDTime(gtd), ""},
+		{"limit GTD without time", NewLimitOrder("EUR_USD", "100", "1.1").WithTimeInForce(TimeInForceGTD), "gtdTime"},
+		{"stop without price", NewStopOrder("EUR_USD", "100", ""), "price"},
+		{"market if touched", NewMarketIfTouchedOrder("EUR_USD", "100", "1.1"), ""},
+		{"take profit", NewTakeProfitOrder("42", "1.2"), ""},
+		{"take profit without trade", NewTakeProfitOrder("", "1.2"), "tradeID"},
+		{"stop loss by distance", NewStopLossOrder("42", "").WithDistance("0.005"), ""},
+		{"stop loss with price and distance", NewStopLossOrder("42", "1.0").WithDistance("0.005"), "price"},
+		{"guaranteed stop loss without price", NewGuaranteedStopLossOrder("42", ""), "price"},
+		{"trailing stop loss", NewTrailingStopLossOrder("42", "0.0050"), ""},
+		{"trailing stop loss without distance", NewTrailingStopLossOrder("42", ""), "distance"},
+		{"trailing stop loss on fill without distance",
+			NewMarketOrder("EUR_USD", "100").WithTrailingStopLossOnFill(OnFill{Price: "1.0"}), "trailingStopLossOnFill"},
+		{"take profit on fill GTD without time",
+			NewLimitOrder("EUR_USD", "100", "1.1").WithTakeProfitOnFill(OnFill{Price: "1.2", TimeInForce: TimeInForceGTD}), "takeProfitOnFill"},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.order.Build()
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+
+		orderErr, ok := err.(*OrderError)
+		if !ok {
+			t.Errorf("%s: expected *OrderError, got %v", tt.name, err)
+			continue
+		}
+		if orderErr.Field != tt.field {
+			t.Errorf("%s: expected error on %s, got %v", tt.name, tt.field, orderErr)
+		}
+	}
+}
+
+func TestOrderBuilderPayload(t *testing.T) {
+	defer logTestResult(t, "OrderBuilderPayload")
+
+	payload, err := NewTrailingStopLossOrder("42", "0.0050").
+		WithClientExtensions(OrderExtensions{ID: "tsl-42"}).
+		Build()
+	assert.NoError(t, err)
+
+	b, err := json.Marshal(payload)
+	assert.NoError(t, err)
+
+	var decoded map[string]map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+
+	order := decoded["order"]
+	assert.Equal(t, "TRAILING_STOP_LOSS", order["type"])
+	assert.Equal(t, "42", order["tradeID"])
+	assert.Equal(t, "0.0050", order["distance"])
+	assert.Equal(t, "GTC", order["timeInForce"])
+	assert.NotContains(t, order, "units")
+	assert.NotContains(t, order, "instrument")
+}
+
+func TestOrderBuilderPayloadTimes(t *testing.T) {
+	defer logTestResult(t, "OrderBuilderPayloadTimes")
+
+	decode := func(payload OrderPayload) map[string]interface{} {
+		b, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		var decoded map[string]map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b, &decoded))
+		return decoded["order"]
+	}
+
+	payload, err := NewLimitOrder("EUR_USD", "100", "1.1").Build()
+	assert.NoError(t, err)
+
+	order := decode(payload)
+	for _, key := range []string{"createTime", "filledTime", "cancelledTime", "gtdTime"} {
+		assert.NotContains(t, order, key)
+	}
+
+	gtd := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	payload, err = NewLimitOrder("EUR_USD", "100", "1.1").WithGTDTime(gtd).Build()
+	assert.NoError(t, err)
+
+	order = decode(payload)
+	assert.Equal(t, "GTD", order["timeInForce"])
+	assert.Equal(t, "2024-01-01T12:00:00Z", order["gtdTime"])
+
+	// Without a time in force the api applies the order type's default
+	payload, err = NewMarketOrder("EUR_USD", "100").WithTimeInForce("").Build()
+	assert.NoError(t, err)
+
+	order = decode(payload)
+	assert.NotContains(t, order, "timeInForce")
+}
@@ -3,6 +3,10 @@ package goanda
 // Supporting OANDA docs - http://developer.oanda.com/rest-live-v20/order-ep/
 
 import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,43 +17,43 @@ type OrderExtensions struct {
 }
 
 type OnFill struct {
-	TimeInForce      string           `json:"timeInForce,omitempty"`
-	Price            string           `json:"price,omitempty"` // must be a string for float precision
-	Distance         string           `json:"distance,omitempty"`
+	TimeInForce      TimeInForce      `json:"timeInForce,omitempty"`
+	Price            Decimal          `json:"price,omitempty"`
+	Distance         Decimal          `json:"distance,omitempty"`
 	GtdTime          string           `json:"gtdTime,omitempty"`
 	ClientExtensions *OrderExtensions `json:"clientExtensions,omitempty"`
 }
 
 type OrderBody struct {
 	ID                       string           `json:"id,omitempty"`
-	CreateTime               time.Time        `json:"createTime,omitempty"`
+	CreateTime               *time.Time       `json:"createTime,omitempty"`
 	State                    string           `json:"state,omitempty"`
 	ClientExtensions         *OrderExtensions `json:"clientExtensions,omitempty"`
-	Instrument               string           `json:"instrument"`
-	Units                    int              `json:"units"`
-	TimeInForce              string           `json:"timeInForce"`
-	PriceBound               string           `json:"priceBound,omitempty"`
-	Type                     string           `json:"type"`
-	PositionFill             string           `json:"positionFill,omitempty"`
-	Price                    string           `json:"price,omitempty"`
+	Instrument               string           `json:"instrument,omitempty"`
+	Units                    Decimal          `json:"units,omitempty"`
+	TimeInForce              TimeInForce      `json:"timeInForce,omitempty"`
+	PriceBound               Decimal          `json:"priceBound,omitempty"`
+	Type                     OrderType        `json:"type"`
+	PositionFill             PositionFill     `json:"positionFill,omitempty"`
+	Price                    Decimal          `json:"price,omitempty"`
 	TakeProfitOnFill         *OnFill          `json:"takeProfitOnFill,omitempty"`
 	StopLossOnFill           *OnFill          `json:"stopLossOnFill,omitempty"`
 	GuaranteedStopLossOnFill *OnFill          `json:"guaranteedStopLossOnFill,omitempty"`
 	TrailingStopLossOnFill   *OnFill          `json:"trailingStopLossOnFill,omitempty"`
 	TradeClientExtensions    *OrderExtensions `json:"tradeClientExtensions,omitempty"`
 	FillingTransactionID     string           `json:"fillingTransactionID,omitempty"`
-	FilledTime               time.Time        `json:"filledTime,omitempty"`
+	FilledTime               *time.Time       `json:"filledTime,omitempty"`
 	TradeOpenedID            string           `json:"tradeOpenedID,omitempty"`
 	TradeID                  string           `json:"tradeID,omitempty"`
 	TradeReducedID           string           `json:"tradeReducedID,omitempty"`
 	TradeClosedIDs           []string         `json:"tradeClosedIDs,omitempty"`
 	CancellingTransactionID  string           `json:"cancellingTransactionID,omitempty"`
-	CancelledTime            time.Time        `json:"cancelledTime,omitempty"`
+	CancelledTime            *time.Time       `json:"cancelledTime,omitempty"`
 	ReplacesOrderID          string           `json:"replacesOrderID,omitempty"`
 	ReplacedByOrderID        string           `json:"replacedByOrderID,omitempty"`
-	TriggerCondition         string           `json:"triggerCondition,omitempty"`
-	GTDTime                  time.Time        `json:"gtdTime,omitempty"`
-	Distance                 string           `json:"distance,omitempty"`
+	TriggerCondition         TriggerCondition `json:"triggerCondition,omitempty"`
+	GTDTime                  *time.Time       `json:"gtdTime,omitempty"`
+	Distance                 Decimal          `json:"distance,omitempty"`
 }
 
 type OrderPayload struct {
@@ -68,10 +72,10 @@ type OrderResponse struct {
 		Time                     time.Time        `json:"time"`
 		TimeInForce              string           `json:"timeInForce"`
 		Type                     string           `json:"type"`
-		Units                    string           `json:"units"`
+		Units                    Decimal          `json:"units"`
 		UserID                   int              `json:"userID"`
-		Price                    string           `json:"price,omitempty"`
-		PriceBound               string           `json:"priceBound,omitempty"`
+		Price                    Decimal          `json:"price,omitempty"`
+		PriceBound               Decimal          `json:"priceBound,omitempty"`
 		Extensions               *OrderExtensions `json:"clientExtensions,omitempty"`
 		TakeProfitOnFill         *OnFill          `json:"takeProfitOnFill,omitempty"`
 		StopLossOnFill           *OnFill          `json:"stopLossOnFill,omitempty"`
@@ -80,41 +84,41 @@ type OrderResponse struct {
 		TradeClientExtensions    *OrderExtensions `json:"tradeClientExtensions,omitempty"`
 		TriggerCondition         string           `json:"triggerCondition,omitempty"`
 		GTDTime                  time.Time        `json:"gtdTime,omitempty"`
-		Distance                 string           `json:"distance,omitempty"`
+		Distance                 Decimal          `json:"distance,omitempty"`
 	} `json:"orderCreateTransaction,omitempty"`
 	OrderFillTransaction struct {
-		AccountBalance string    `json:"accountBalance"`
+		AccountBalance Decimal   `json:"accountBalance"`
 		AccountID      string    `json:"accountID"`
 		BatchID        string    `json:"batchID"`
-		Financing      string    `json:"financing"`
+		Financing      Decimal   `json:"financing"`
 		ID             string    `json:"id"`
 		Instrument     string    `json:"instrument"`
 		OrderID        string    `json:"orderID"`
-		Pl             string    `json:"pl"`
-		Price          string    `json:"price"`
+		Pl             Decimal   `json:"pl"`
+		Price          Decimal   `json:"price"`
 		Reason         string    `json:"reason"`
 		Time           time.Time `json:"time"`
 		TradeOpened    struct {
-			TradeID string `json:"tradeID"`
-			Units   string `json:"units"`
+			TradeID string  `json:"tradeID"`
+			Units   Decimal `json:"units"`
 		} `json:"tradeOpened"`
-		Type      string `json:"type"`
-		Units     string `json:"units"`
-		UserID    int    `json:"userID"`
-		FullPrice FullPrice `json:"fullPrice,omitempty"`
+		Type         string    `json:"type"`
+		Units        Decimal   `json:"units"`
+		UserID       int       `json:"userID"`
+		FullPrice    FullPrice `json:"fullPrice,omitempty"`
 		TradesClosed []struct {
-			TradeID    string `json:"tradeID"`
-			Units      string `json:"units"`
-			Financing  string `json:"financing"`
-			RealizedPL string `json:"realizedPL"`
-			Price      string `json:"price"`
+			TradeID    string  `json:"tradeID"`
+			Units      Decimal `json:"units"`
+			Financing  Decimal `json:"financing"`
+			RealizedPL Decimal `json:"realizedPL"`
+			Price      Decimal `json:"price"`
 		} `json:"tradesClosed,omitempty"`
 		TradeReduced struct {
-			TradeID    string `json:"tradeID"`
-			Units      string `json:"units"`
-			Financing  string `json:"financing"`
-			RealizedPL string `json:"realizedPL"`
-			Price      string `json:"price"`
+			TradeID    string  `json:"tradeID"`
+			Units      Decimal `json:"units"`
+			Financing  Decimal `json:"financing"`
+			RealizedPL Decimal `json:"realizedPL"`
+			Price      Decimal `json:"price"`
 		} `json:"tradeReduced,omitempty"`
 	} `json:"orderFillTransaction,omitempty"`
 	OrderCancelTransaction struct {
@@ -147,12 +151,12 @@ type OrderInfo struct {
 	State                    string           `json:"state"`
 	ClientExtensions         *OrderExtensions `json:"clientExtensions,omitempty"`
 	Instrument               string           `json:"instrument"`
-	Units                    string           `json:"units"` // Keep as string for API response
+	Units                    Decimal          `json:"units"`
 	TimeInForce              string           `json:"timeInForce"`
-	PriceBound               string           `json:"priceBound,omitempty"`
+	PriceBound               Decimal          `json:"priceBound,omitempty"`
 	Type                     string           `json:"type"`
 	PositionFill             string           `json:"positionFill"`
-	Price                    string           `json:"price,omitempty"`
+	Price                    Decimal          `json:"price,omitempty"`
 	TakeProfitOnFill         *OnFill          `json:"takeProfitOnFill,omitempty"`
 	StopLossOnFill           *OnFill          `json:"stopLossOnFill,omitempty"`
 	GuaranteedStopLossOnFill *OnFill          `json:"guaranteedStopLossOnFill,omitempty"`
@@ -170,7 +174,8 @@ type OrderInfo struct {
 	TriggerCondition         string           `json:"triggerCondition,omitempty"`
 	GTDTime                  time.Time        `json:"gtdTime,omitempty"`
 	PartialFill              string           `json:"partialFill,omitempty"`
-	Distance                 string           `json:"distance,omitempty"`
+	Distance                 Decimal          `json:"distance,omitempty"`
+	TrailingStopValue        Decimal          `json:"trailingStopValue,omitempty"`
 }
 
 type RetrievedOrders struct {
@@ -201,63 +206,271 @@ type CancelledOrder struct {
 }
 
 func (c *Connection) CreateOrder(body OrderPayload) (OrderResponse, error) {
+	return c.CreateOrderContext(context.Background(), body)
+}
+
+// CreateOrderContext is CreateOrder bound to the given context
+func (c *Connection) CreateOrderContext(ctx context.Context, body OrderPayload) (OrderResponse, error) {
 	or := OrderResponse{}
-	err := c.postAndUnmarshal("/accounts/"+c.accountID+"/orders", body, &or)
+	if err := c.validateOrderUnits(ctx, body.Order); err != nil {
+		return or, err
+	}
+	// A client order ID makes the api reject duplicates, so only then is it safe to retry
+	idempotent := body.Order.ClientExtensions != nil && body.Order.ClientExtensions.ID != ""
+	err := c.postAndUnmarshal(ctx, "/accounts/"+c.accountID+"/orders", body, &or, idempotent)
 	return or, err
 }
 
 func (c *Connection) GetOrders(instrument string) (RetrievedOrders, error) {
-	endpoint := "/accounts/" + c.accountID + "/orders"
-	if instrument != "" {
-		endpoint = endpoint + "?instrument=" + instrument
+	return c.GetOrdersContext(context.Background(), instrument)
+}
+
+// GetOrdersContext is GetOrders bound to the given context
+func (c *Connection) GetOrdersContext(ctx context.Context, instrument string) (RetrievedOrders, error) {
+	return c.ListOrdersContext(ctx, OrderListOptions{Instrument: instrument})
+}
+
+// OrderState is the state of an order, used to filter the orders listed
+type OrderState string
+
+const (
+	OrderStatePending   OrderState = "PENDING"
+	OrderStateFilled    OrderState = "FILLED"
+	OrderStateTriggered OrderState = "TRIGGERED"
+	OrderStateCancelled OrderState = "CANCELLED"
+	OrderStateAll       OrderState = "ALL"
+)
+
+// OrderListOptions filters the orders returned by ListOrders. Zero values are left
+// to the api's defaults, which list up to 50 pending orders.
+type OrderListOptions struct {
+	IDs        []string
+	State      OrderState
+	Instrument string
+	// Count is the maximum number of orders to return, at most 500
+	Count int
+	// BeforeID only returns orders with an ID less than the given one
+	BeforeID string
+}
+
+func (o OrderListOptions) query() string {
+	q := url.Values{}
+	if len(o.IDs) > 0 {
+		q.Set("ids", strings.Join(o.IDs, ","))
+	}
+	if o.State != "" {
+		q.Set("state", string(o.State))
+	}
+	if o.Instrument != "" {
+		q.Set("instrument", o.Instrument)
+	}
+	if o.Count > 0 {
+		q.Set("count", strconv.Itoa(o.Count))
 	}
+	if o.BeforeID != "" {
+		q.Set("beforeID", o.BeforeID)
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
 
+// ListOrders returns the account's orders matching the options, most recent first
+func (c *Connection) ListOrders(opts OrderListOptions) (RetrievedOrders, error) {
+	return c.ListOrdersContext(context.Background(), opts)
+}
+
+// ListOrdersContext is ListOrders bound to the given context
+func (c *Connection) ListOrdersContext(ctx context.Context, opts OrderListOptions) (RetrievedOrders, error) {
 	ro := RetrievedOrders{}
-	err := c.getAndUnmarshal(endpoint, &ro)
+	err := c.getAndUnmarshal(ctx, "/accounts/"+c.accountID+"/orders"+opts.query(), &ro)
 	return ro, err
 }
 
+// OrderIterator pages backwards through the account's order history, see IterateOrders
+type OrderIterator struct {
+	c     *Connection
+	ctx   context.Context
+	opts  OrderListOptions
+	page  []OrderInfo
+	order OrderInfo
+	done  bool
+	err   error
+}
+
+// IterateOrders returns an iterator over the orders matching the options, most recent
+// first. Each page is requested with the options' Count, then the next page with a
+// BeforeID of the oldest order seen, until a page comes back short or the caller
+// stops calling Next.
+//
+//	it := c.IterateOrders(ctx, goanda.OrderListOptions{State: goanda.OrderStateCancelled})
+//	for it.Next() {
+//		order := it.Order()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func (c *Connection) IterateOrders(ctx context.Context, opts OrderListOptions) *OrderIterator {
+	if opts.Count <= 0 {
+		opts.Count = 50
+	}
+	return &OrderIterator{c: c, ctx: ctx, opts: opts}
+}
+
+// Next advances to the next order, fetching the next page when needed. It returns false
+// when there are no more orders or a request failed, see Err.
+func (it *OrderIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+
+		ro, err := it.c.ListOrdersContext(it.ctx, it.opts)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = ro.Orders
+		if len(it.page) < it.opts.Count {
+			it.done = true
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+		it.opts.BeforeID = it.page[len(it.page)-1].ID
+	}
+
+	it.order, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Order returns the current order
+func (it *OrderIterator) Order() OrderInfo {
+	return it.order
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *OrderIterator) Err() error {
+	return it.err
+}
+
 func (c *Connection) GetPendingOrders() (RetrievedOrders, error) {
+	return c.GetPendingOrdersContext(context.Background())
+}
+
+// GetPendingOrdersContext is GetPendingOrders bound to the given context
+func (c *Connection) GetPendingOrdersContext(ctx context.Context) (RetrievedOrders, error) {
 	ro := RetrievedOrders{}
-	err := c.getAndUnmarshal("/accounts/"+c.accountID+"/pendingOrders", &ro)
+	err := c.getAndUnmarshal(ctx, "/accounts/"+c.accountID+"/pendingOrders", &ro)
 	return ro, err
 }
 
+// ClientIDSpecifier returns the specifier of the order or trade with the given client
+// ID, for use in place of an order or trade ID
+func ClientIDSpecifier(clientID string) string {
+	return "@" + clientID
+}
+
+// GetOrder returns the order with the given ID, or the given client ID using ClientIDSpecifier
 func (c *Connection) GetOrder(orderSpecifier string) (RetrievedOrder, error) {
+	return c.GetOrderContext(context.Background(), orderSpecifier)
+}
+
+// GetOrderContext is GetOrder bound to the given context
+func (c *Connection) GetOrderContext(ctx context.Context, orderSpecifier string) (RetrievedOrder, error) {
 	ro := RetrievedOrder{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/orders/"+
-			orderSpecifier,
+			url.PathEscape(orderSpecifier),
 		&ro,
 	)
 	return ro, err
 }
 
 func (c *Connection) UpdateOrder(orderSpecifier string, body OrderPayload) (RetrievedOrder, error) {
+	return c.UpdateOrderContext(context.Background(), orderSpecifier, body)
+}
+
+// UpdateOrderContext is UpdateOrder bound to the given context
+func (c *Connection) UpdateOrderContext(ctx context.Context, orderSpecifier string, body OrderPayload) (RetrievedOrder, error) {
 	ro := RetrievedOrder{}
+	if err := c.validateOrderUnits(ctx, body.Order); err != nil {
+		return ro, err
+	}
 	err := c.putAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/orders/"+
-			orderSpecifier,
+			url.PathEscape(orderSpecifier),
 		body,
 		&ro,
+		true,
 	)
 	return ro, err
 }
 
+// CancelOrder cancels the order with the given ID, or the given client ID using ClientIDSpecifier
 func (c *Connection) CancelOrder(orderSpecifier string) (CancelledOrder, error) {
+	return c.CancelOrderContext(context.Background(), orderSpecifier)
+}
+
+// CancelOrderContext is CancelOrder bound to the given context
+func (c *Connection) CancelOrderContext(ctx context.Context, orderSpecifier string) (CancelledOrder, error) {
 	co := CancelledOrder{}
 	err := c.putAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/orders/"+
-			orderSpecifier+
+			url.PathEscape(orderSpecifier)+
 			"/cancel",
 		nil,
 		&co,
+		true,
 	)
 	return co, err
 }
+
+// OrderClientExtensionsResponse holds the transaction created by UpdateOrderClientExtensions
+type OrderClientExtensionsResponse struct {
+	OrderClientExtensionsModifyTransaction OrderClientExtensionsModifyTransaction `json:"orderClientExtensionsModifyTransaction"`
+	RelatedTransactionIDs                  []string                               `json:"relatedTransactionIDs"`
+	LastTransactionID                      string                                 `json:"lastTransactionID"`
+}
+
+// UpdateOrderClientExtensions replaces the client extensions of a pending order, and of
+// the trade it will open when filled. Either may be nil to leave it unchanged.
+func (c *Connection) UpdateOrderClientExtensions(orderSpecifier string, clientExtensions *OrderExtensions, tradeClientExtensions *OrderExtensions) (OrderClientExtensionsResponse, error) {
+	return c.UpdateOrderClientExtensionsContext(context.Background(), orderSpecifier, clientExtensions, tradeClientExtensions)
+}
+
+// UpdateOrderClientExtensionsContext is UpdateOrderClientExtensions bound to the given context
+func (c *Connection) UpdateOrderClientExtensionsContext(ctx context.Context, orderSpecifier string, clientExtensions *OrderExtensions, tradeClientExtensions *OrderExtensions) (OrderClientExtensionsResponse, error) {
+	body := struct {
+		ClientExtensions      *OrderExtensions `json:"clientExtensions,omitempty"`
+		TradeClientExtensions *OrderExtensions `json:"tradeClientExtensions,omitempty"`
+	}{clientExtensions, tradeClientExtensions}
+
+	or := OrderClientExtensionsResponse{}
+	err := c.putAndUnmarshal(
+		ctx,
+		"/accounts/"+
+			c.accountID+
+			"/orders/"+
+			url.PathEscape(orderSpecifier)+
+			"/clientExtensions",
+		body,
+		&or,
+		true,
+	)
+	return or, err
+}
@@ -1,12 +1,11 @@
 package goanda
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
-	"strconv"
 	"testing"
 	"time"
 
@@ -23,7 +22,7 @@ func TestCreateOrder(t *testing.T) {
 		err := json.NewDecoder(r.Body).Decode(&payload)
 		assert.NoError(t, err)
 
-		units := strconv.Itoa(payload.Order.Units)
+		units := payload.Order.Units
 
 		response := OrderResponse{
 			LastTransactionID: "1000",
@@ -37,10 +36,10 @@ func TestCreateOrder(t *testing.T) {
 				Time                     time.Time        `json:"time"`
 				TimeInForce              string           `json:"timeInForce"`
 				Type                     string           `json:"type"`
-				Units                    string           `json:"units"`
+				Units                    Decimal          `json:"units"`
 				UserID                   int              `json:"userID"`
-				Price                    string           `json:"price,omitempty"`
-				PriceBound               string           `json:"priceBound,omitempty"`
+				Price                    Decimal          `json:"price,omitempty"`
+				PriceBound               Decimal          `json:"priceBound,omitempty"`
 				Extensions               *OrderExtensions `json:"clientExtensions,omitempty"`
 				TakeProfitOnFill         *OnFill          `json:"takeProfitOnFill,omitempty"`
 				StopLossOnFill           *OnFill          `json:"stopLossOnFill,omitempty"`
@@ -49,14 +48,14 @@ func TestCreateOrder(t *testing.T) {
 				TradeClientExtensions    *OrderExtensions `json:"tradeClientExtensions,omitempty"`
 				TriggerCondition         string           `json:"triggerCondition,omitempty"`
 				GTDTime                  time.Time        `json:"gtdTime,omitempty"`
-				Distance                 string           `json:"distance,omitempty"`
+				Distance                 Decimal          `json:"distance,omitempty"`
 			}{
 				ID:           "1000",
 				Type:         "MARKET_ORDER",
 				Instrument:   payload.Order.Instrument,
 				Units:        units,
-				TimeInForce:  payload.Order.TimeInForce,
-				PositionFill: payload.Order.PositionFill,
+				TimeInForce:  string(payload.Order.TimeInForce),
+				PositionFill: string(payload.Order.PositionFill),
 			},
 		}
 		json.NewEncoder(w).Encode(response)
@@ -73,7 +72,7 @@ func TestCreateOrder(t *testing.T) {
 		Order: OrderBody{
 			Type:         "MARKET",
 			Instrument:   "EUR_USD",
-			Units:        100,
+			Units:        "100",
 			TimeInForce:  "FOK",
 			PositionFill: "DEFAULT",
 			TakeProfitOnFill: &OnFill{
@@ -98,7 +97,7 @@ func TestCreateOrder(t *testing.T) {
 	assert.Equal(t, "1000", response.LastTransactionID)
 	assert.Equal(t, "MARKET_ORDER", response.OrderCreateTransaction.Type)
 	assert.Equal(t, "EUR_USD", response.OrderCreateTransaction.Instrument)
-	assert.Equal(t, "100", response.OrderCreateTransaction.Units)
+	assert.Equal(t, Decimal("100"), response.OrderCreateTransaction.Units)
 	assert.Equal(t, "FOK", response.OrderCreateTransaction.TimeInForce)
 	assert.Equal(t, "DEFAULT", response.OrderCreateTransaction.PositionFill)
 }
@@ -148,14 +147,84 @@ func TestGetOrders(t *testing.T) {
 	assert.Equal(t, "1", order.ID)
 	assert.Equal(t, "LIMIT", order.Type)
 	assert.Equal(t, "EUR_USD", order.Instrument)
-	assert.Equal(t, "100", order.Units)
-	assert.Equal(t, "1.1000", order.Price)
+	assert.Equal(t, Decimal("100"), order.Units)
+	assert.Equal(t, Decimal("1.1000"), order.Price)
 	assert.Equal(t, "PENDING", order.State)
 	assert.Equal(t, "GTC", order.TimeInForce)
 	assert.Equal(t, "DEFAULT", order.PositionFill)
 	assert.Equal(t, "DEFAULT", order.TriggerCondition)
 }
 
+func TestListOrders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/accounts/test-account/orders", r.URL.Path)
+
+		q := r.URL.Query()
+		assert.Equal(t, "1,2", q.Get("ids"))
+		assert.Equal(t, "CANCELLED", q.Get("state"))
+		assert.Equal(t, "EUR_USD", q.Get("instrument"))
+		assert.Equal(t, "10", q.Get("count"))
+		assert.Equal(t, "3", q.Get("beforeID"))
+
+		json.NewEncoder(w).Encode(RetrievedOrders{LastTransactionID: "1000"})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	_, err := c.ListOrders(OrderListOptions{
+		IDs:        []string{"1", "2"},
+		State:      OrderStateCancelled,
+		Instrument: "EUR_USD",
+		Count:      10,
+		BeforeID:   "3",
+	})
+	assert.NoError(t, err)
+}
+
+func TestIterateOrders(t *testing.T) {
+	var beforeIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("count"))
+		assert.Equal(t, "ALL", r.URL.Query().Get("state"))
+
+		beforeID := r.URL.Query().Get("beforeID")
+		beforeIDs = append(beforeIDs, beforeID)
+
+		var orders []OrderInfo
+		switch beforeID {
+		case "":
+			orders = []OrderInfo{{ID: "5"}, {ID: "4"}}
+		case "4":
+			orders = []OrderInfo{{ID: "3"}, {ID: "2"}}
+		case "2":
+			orders = []OrderInfo{{ID: "1"}}
+		}
+		json.NewEncoder(w).Encode(RetrievedOrders{Orders: orders})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	var ids []string
+	it := c.IterateOrders(context.Background(), OrderListOptions{State: OrderStateAll, Count: 2})
+	for it.Next() {
+		ids = append(ids, it.Order().ID)
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"5", "4", "3", "2", "1"}, ids)
+	assert.Equal(t, []string{"", "4", "2"}, beforeIDs)
+}
+
 func TestGetOrder(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/accounts/test-account/orders/1", r.URL.Path)
@@ -200,15 +269,15 @@ func TestGetOrder(t *testing.T) {
 	assert.Equal(t, "1", order.Order.ID)
 	assert.Equal(t, "LIMIT", order.Order.Type)
 	assert.Equal(t, "EUR_USD", order.Order.Instrument)
-	assert.Equal(t, "100", order.Order.Units)
-	assert.Equal(t, "1.1000", order.Order.Price)
+	assert.Equal(t, Decimal("100"), order.Order.Units)
+	assert.Equal(t, Decimal("1.1000"), order.Order.Price)
 	assert.Equal(t, "PENDING", order.Order.State)
 	assert.Equal(t, "GTC", order.Order.TimeInForce)
 	assert.Equal(t, "DEFAULT", order.Order.PositionFill)
 	assert.Equal(t, "DEFAULT", order.Order.TriggerCondition)
-	assert.Equal(t, "1.2000", order.Order.TakeProfitOnFill.Price)
-	assert.Equal(t, "1.0000", order.Order.StopLossOnFill.Price)
-	assert.Equal(t, "0.0100", order.Order.TrailingStopLossOnFill.Distance)
+	assert.Equal(t, Decimal("1.2000"), order.Order.TakeProfitOnFill.Price)
+	assert.Equal(t, Decimal("1.0000"), order.Order.StopLossOnFill.Price)
+	assert.Equal(t, Decimal("0.0100"), order.Order.TrailingStopLossOnFill.Distance)
 }
 
 func TestUpdateOrder(t *testing.T) {
@@ -220,19 +289,19 @@ func TestUpdateOrder(t *testing.T) {
 		err := json.NewDecoder(r.Body).Decode(&payload)
 		assert.NoError(t, err)
 
-		units := strconv.Itoa(payload.Order.Units)
+		units := payload.Order.Units
 
 		response := RetrievedOrder{
 			Order: OrderInfo{
 				ID:                     "1",
 				CreateTime:             time.Now(),
-				Type:                   payload.Order.Type,
+				Type:                   string(payload.Order.Type),
 				Instrument:             payload.Order.Instrument,
 				Units:                  units,
 				Price:                  payload.Order.Price,
 				State:                  "PENDING",
-				TimeInForce:            payload.Order.TimeInForce,
-				PositionFill:           payload.Order.PositionFill,
+				TimeInForce:            string(payload.Order.TimeInForce),
+				PositionFill:           string(payload.Order.PositionFill),
 				TriggerCondition:       "DEFAULT",
 				TakeProfitOnFill:       payload.Order.TakeProfitOnFill,
 				StopLossOnFill:         payload.Order.StopLossOnFill,
@@ -253,7 +322,7 @@ func TestUpdateOrder(t *testing.T) {
 		Order: OrderBody{
 			Type:         "LIMIT",
 			Instrument:   "EUR_USD",
-			Units:        200,
+			Units:        "200",
 			Price:        "1.1100",
 			TimeInForce:  "GTC",
 			PositionFill: "DEFAULT",
@@ -275,15 +344,15 @@ func TestUpdateOrder(t *testing.T) {
 	assert.Equal(t, "1", order.Order.ID)
 	assert.Equal(t, "LIMIT", order.Order.Type)
 	assert.Equal(t, "EUR_USD", order.Order.Instrument)
-	assert.Equal(t, "200", order.Order.Units)
-	assert.Equal(t, "1.1100", order.Order.Price)
+	assert.Equal(t, Decimal("200"), order.Order.Units)
+	assert.Equal(t, Decimal("1.1100"), order.Order.Price)
 	assert.Equal(t, "PENDING", order.Order.State)
 	assert.Equal(t, "GTC", order.Order.TimeInForce)
 	assert.Equal(t, "DEFAULT", order.Order.PositionFill)
 	assert.Equal(t, "DEFAULT", order.Order.TriggerCondition)
-	assert.Equal(t, "1.2100", order.Order.TakeProfitOnFill.Price)
-	assert.Equal(t, "1.0100", order.Order.StopLossOnFill.Price)
-	assert.Equal(t, "0.0200", order.Order.TrailingStopLossOnFill.Distance)
+	assert.Equal(t, Decimal("1.2100"), order.Order.TakeProfitOnFill.Price)
+	assert.Equal(t, Decimal("1.0100"), order.Order.StopLossOnFill.Price)
+	assert.Equal(t, Decimal("0.0200"), order.Order.TrailingStopLossOnFill.Distance)
 }
 
 func TestCancelOrder(t *testing.T) {
@@ -371,20 +440,17 @@ func TestOrderIntegration(t *testing.T) {
 	if len(pricing.Prices) == 0 {
 		t.Fatalf("No pricing information received for %s", instrument)
 	}
-	currentPrice, err := strconv.ParseFloat(pricing.Prices[0].CloseoutAsk, 64)
-	if err != nil {
-		t.Fatalf("Error parsing current price: %v", err)
-	}
+	currentPrice := pricing.Prices[0].CloseoutAsk
 
 	// Calculate take profit and stop loss prices
-	takeProfitPrice := fmt.Sprintf("%.5f", currentPrice*1.01) // 1% above current price
-	stopLossPrice := fmt.Sprintf("%.5f", currentPrice*0.99)   // 1% below current price
-	trailingStopDistance := "0.00500"                         // 50 pips
+	takeProfitPrice := currentPrice.Mul("1.01").Round(5) // 1% above current price
+	stopLossPrice := currentPrice.Mul("0.99").Round(5)   // 1% below current price
+	trailingStopDistance := Decimal("0.00500")           // 50 pips
 
 	// Create a new order
 	orderBody := OrderBody{
 		Instrument:   instrument,
-		Units:        100,
+		Units:        "100",
 		Type:         "MARKET",
 		TimeInForce:  "FOK",
 		PositionFill: "DEFAULT",
@@ -473,8 +539,64 @@ func TestOrderIntegration(t *testing.T) {
 
 	// Clean up: close the position
 	time.Sleep(5 * time.Second)
-	_, err = conn.ReduceTradeSize(trade.ID, CloseTradePayload{Units: "ALL"})
+	_, err = conn.ReduceTradeSize(trade.ID, CloseTradePayload{Units: CloseAll})
 	if err != nil {
 		t.Fatalf("Error closing position: %v", err)
 	}
 }
+
+func TestGetOrderByClientID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/accounts/test-account/orders/@strategy%2F7%20a", r.URL.EscapedPath())
+		json.NewEncoder(w).Encode(RetrievedOrder{Order: OrderInfo{ID: "1"}})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	order, err := c.GetOrder(ClientIDSpecifier("strategy/7 a"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", order.Order.ID)
+}
+
+func TestUpdateOrderClientExtensions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/accounts/test-account/orders/@my-order/clientExtensions", r.URL.Path)
+		assert.Equal(t, "PUT", r.Method)
+
+		var payload map[string]json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.JSONEq(t, `{"tag":"strategy-2"}`, string(payload["tradeClientExtensions"]))
+		assert.NotContains(t, payload, "clientExtensions")
+
+		w.Write([]byte(`{
+			"orderClientExtensionsModifyTransaction": {
+				"id": "200",
+				"type": "ORDER_CLIENT_EXTENSIONS_MODIFY",
+				"orderID": "1",
+				"clientOrderID": "my-order",
+				"tradeClientExtensionsModify": {"tag": "strategy-2"}
+			},
+			"lastTransactionID": "200"
+		}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	response, err := c.UpdateOrderClientExtensions(ClientIDSpecifier("my-order"), nil, &OrderExtensions{Tag: "strategy-2"})
+	assert.NoError(t, err)
+
+	transaction := response.OrderClientExtensionsModifyTransaction
+	assert.Equal(t, "200", transaction.ID)
+	assert.Equal(t, "1", transaction.OrderID)
+	assert.Equal(t, "strategy-2", transaction.TradeClientExtensionsModify.Tag)
+}
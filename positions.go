@@ -2,40 +2,135 @@ package goanda
 
 // Supporting OANDA docs - http://developer.oanda.com/rest-live-v20/position-ep/
 
+import (
+	"context"
+	"net/url"
+)
+
 type OpenPositions struct {
-	LastTransactionID string `json:"lastTransactionID"`
-	Positions         []struct {
-		Instrument string `json:"instrument"`
-		Long       struct {
-			AveragePrice string   `json:"averagePrice"`
-			Pl           string   `json:"pl"`
-			ResettablePL string   `json:"resettablePL"`
-			TradeIDs     []string `json:"tradeIDs"`
-			Units        string   `json:"units"`
-			UnrealizedPL string   `json:"unrealizedPL"`
-		} `json:"long"`
-		Pl           string `json:"pl"`
-		ResettablePL string `json:"resettablePL"`
-		Short        struct {
-			AveragePrice string   `json:"averagePrice"`
-			Pl           string   `json:"pl"`
-			ResettablePL string   `json:"resettablePL"`
-			TradeIDs     []string `json:"tradeIDs"`
-			Units        string   `json:"units"`
-			UnrealizedPL string   `json:"unrealizedPL"`
-		} `json:"short"`
-		UnrealizedPL string `json:"unrealizedPL"`
-	} `json:"positions"`
+	LastTransactionID string     `json:"lastTransactionID"`
+	Positions         []Position `json:"positions"`
+}
+
+// Position is the account's long and short position in an instrument
+type Position struct {
+	Instrument              string       `json:"instrument"`
+	PL                      Decimal      `json:"pl"`
+	UnrealizedPL            Decimal      `json:"unrealizedPL"`
+	MarginUsed              Decimal      `json:"marginUsed"`
+	ResettablePL            Decimal      `json:"resettablePL"`
+	Financing               Decimal      `json:"financing"`
+	Commission              Decimal      `json:"commission"`
+	GuaranteedExecutionFees Decimal      `json:"guaranteedExecutionFees"`
+	Long                    PositionSide `json:"long"`
+	Short                   PositionSide `json:"short"`
+}
+
+// PositionSide is one side of a position. Units are negative for the short side.
+type PositionSide struct {
+	Units                   Decimal  `json:"units"`
+	AveragePrice            Decimal  `json:"averagePrice,omitempty"`
+	TradeIDs                []string `json:"tradeIDs,omitempty"`
+	PL                      Decimal  `json:"pl"`
+	UnrealizedPL            Decimal  `json:"unrealizedPL"`
+	ResettablePL            Decimal  `json:"resettablePL"`
+	Financing               Decimal  `json:"financing"`
+	GuaranteedExecutionFees Decimal  `json:"guaranteedExecutionFees"`
 }
 
+type Positions struct {
+	LastTransactionID string     `json:"lastTransactionID"`
+	Positions         []Position `json:"positions"`
+}
+
+type RetrievedPosition struct {
+	LastTransactionID string   `json:"lastTransactionID"`
+	Position          Position `json:"position"`
+}
+
+// CloseUnits is how much of a trade or of one side of a position to close: ALL, a number
+// of units, or NONE for a side of a position
+type CloseUnits string
+
+const (
+	CloseAll  CloseUnits = "ALL"
+	CloseNone CloseUnits = "NONE"
+)
+
+// CloseUnitsOf closes the given number of units of a trade or a side of a position
+func CloseUnitsOf(units Decimal) CloseUnits {
+	return CloseUnits(units.String())
+}
+
+// ClosePositionPayload specifies how much of each side of a position to close. A side
+// left empty is left to the api's default, which is NONE.
 type ClosePositionPayload struct {
-	LongUnits  string `json:"longUnits"`
-	ShortUnits string `json:"shortUnits"`
+	LongUnits             CloseUnits       `json:"longUnits,omitempty"`
+	LongClientExtensions  *OrderExtensions `json:"longClientExtensions,omitempty"`
+	ShortUnits            CloseUnits       `json:"shortUnits,omitempty"`
+	ShortClientExtensions *OrderExtensions `json:"shortClientExtensions,omitempty"`
+}
+
+// ClosePositionResponse holds the transactions of the market orders closing each side
+// of the position. Only the transactions of the sides being closed are set.
+type ClosePositionResponse struct {
+	LongOrderCreateTransaction  *MarketOrderTransaction `json:"longOrderCreateTransaction,omitempty"`
+	LongOrderFillTransaction    *OrderFillTransaction   `json:"longOrderFillTransaction,omitempty"`
+	LongOrderCancelTransaction  *OrderCancelTransaction `json:"longOrderCancelTransaction,omitempty"`
+	ShortOrderCreateTransaction *MarketOrderTransaction `json:"shortOrderCreateTransaction,omitempty"`
+	ShortOrderFillTransaction   *OrderFillTransaction   `json:"shortOrderFillTransaction,omitempty"`
+	ShortOrderCancelTransaction *OrderCancelTransaction `json:"shortOrderCancelTransaction,omitempty"`
+	RelatedTransactionIDs       []string                `json:"relatedTransactionIDs"`
+	LastTransactionID           string                  `json:"lastTransactionID"`
+}
+
+// GetPositions returns the account's positions in every instrument it has traded,
+// including those with no units open
+func (c *Connection) GetPositions() (Positions, error) {
+	return c.GetPositionsContext(context.Background())
+}
+
+// GetPositionsContext is GetPositions bound to the given context
+func (c *Connection) GetPositionsContext(ctx context.Context) (Positions, error) {
+	p := Positions{}
+	err := c.getAndUnmarshal(
+		ctx,
+		"/accounts/"+
+			c.accountID+
+			"/positions",
+		&p,
+	)
+	return p, err
+}
+
+// GetPosition returns the account's position in the instrument
+func (c *Connection) GetPosition(instrument string) (RetrievedPosition, error) {
+	return c.GetPositionContext(context.Background(), instrument)
+}
+
+// GetPositionContext is GetPosition bound to the given context
+func (c *Connection) GetPositionContext(ctx context.Context, instrument string) (RetrievedPosition, error) {
+	rp := RetrievedPosition{}
+	err := c.getAndUnmarshal(
+		ctx,
+		"/accounts/"+
+			c.accountID+
+			"/positions/"+
+			url.PathEscape(instrument),
+		&rp,
+	)
+	return rp, err
 }
 
 func (c *Connection) GetOpenPositions() (OpenPositions, error) {
+	return c.GetOpenPositionsContext(context.Background())
+}
+
+// GetOpenPositionsContext is GetOpenPositions bound to the given context
+func (c *Connection) GetOpenPositionsContext(ctx context.Context) (OpenPositions, error) {
 	op := OpenPositions{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/openPositions",
@@ -44,16 +139,33 @@ func (c *Connection) GetOpenPositions() (OpenPositions, error) {
 	return op, err
 }
 
-func (c *Connection) ClosePosition(instrument string, body ClosePositionPayload) (ModifiedTrade, error) {
-	mt := ModifiedTrade{}
+// wholeSides reports whether the payload closes each side of the position entirely or not
+// at all, which is the only way to close a position that can safely be sent twice
+func (p ClosePositionPayload) wholeSides() bool {
+	whole := func(units CloseUnits) bool {
+		return units == "" || units == CloseAll || units == CloseNone
+	}
+	return whole(p.LongUnits) && whole(p.ShortUnits)
+}
+
+// ClosePosition closes some or all of the long and short sides of the position in the instrument
+func (c *Connection) ClosePosition(instrument string, body ClosePositionPayload) (ClosePositionResponse, error) {
+	return c.ClosePositionContext(context.Background(), instrument, body)
+}
+
+// ClosePositionContext is ClosePosition bound to the given context
+func (c *Connection) ClosePositionContext(ctx context.Context, instrument string, body ClosePositionPayload) (ClosePositionResponse, error) {
+	cr := ClosePositionResponse{}
 	err := c.putAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/positions/"+
-			instrument+
+			url.PathEscape(instrument)+
 			"/close",
 		body,
-		&mt,
+		&cr,
+		body.wholeSides(),
 	)
-	return mt, err
+	return cr, err
 }
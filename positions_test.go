@@ -20,46 +20,18 @@ func TestGetOpenPositions(t *testing.T) {
 
 		response := OpenPositions{
 			LastTransactionID: "1000",
-			Positions: []struct {
-				Instrument string `json:"instrument"`
-				Long       struct {
-					AveragePrice string   `json:"averagePrice"`
-					Pl           string   `json:"pl"`
-					ResettablePL string   `json:"resettablePL"`
-					TradeIDs     []string `json:"tradeIDs"`
-					Units        string   `json:"units"`
-					UnrealizedPL string   `json:"unrealizedPL"`
-				} `json:"long"`
-				Pl           string `json:"pl"`
-				ResettablePL string `json:"resettablePL"`
-				Short        struct {
-					AveragePrice string   `json:"averagePrice"`
-					Pl           string   `json:"pl"`
-					ResettablePL string   `json:"resettablePL"`
-					TradeIDs     []string `json:"tradeIDs"`
-					Units        string   `json:"units"`
-					UnrealizedPL string   `json:"unrealizedPL"`
-				} `json:"short"`
-				UnrealizedPL string `json:"unrealizedPL"`
-			}{
+			Positions: []Position{
 				{
 					Instrument: "EUR_USD",
-					Long: struct {
-						AveragePrice string   `json:"averagePrice"`
-						Pl           string   `json:"pl"`
-						ResettablePL string   `json:"resettablePL"`
-						TradeIDs     []string `json:"tradeIDs"`
-						Units        string   `json:"units"`
-						UnrealizedPL string   `json:"unrealizedPL"`
-					}{
+					Long: PositionSide{
 						AveragePrice: "1.1000",
-						Pl:           "10.00",
+						PL:           "10.00",
 						ResettablePL: "10.00",
 						TradeIDs:     []string{"1", "2"},
 						Units:        "100",
 						UnrealizedPL: "5.00",
 					},
-					Pl:           "10.00",
+					PL:           "10.00",
 					ResettablePL: "10.00",
 					UnrealizedPL: "5.00",
 				},
@@ -100,6 +72,84 @@ func TestGetOpenPositions(t *testing.T) {
 	}
 }
 
+func TestGetPositions(t *testing.T) {
+	defer logTestResult(t, "GetPositions")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/test-account/positions" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+
+		w.Write([]byte(`{
+			"positions": [
+				{"instrument": "EUR_USD", "pl": "12.5", "long": {"units": "100", "averagePrice": "1.10000", "tradeIDs": ["1"]}, "short": {"units": "0"}},
+				{"instrument": "USD_JPY", "pl": "-3.2", "long": {"units": "0"}, "short": {"units": "0"}}
+			],
+			"lastTransactionID": "1000"
+		}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	positions, err := c.GetPositions()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(positions.Positions) != 2 {
+		t.Fatalf("Expected 2 positions, got %d", len(positions.Positions))
+	}
+	if positions.Positions[0].Long.AveragePrice != "1.10000" {
+		t.Errorf("Expected Long.AveragePrice to be 1.10000, got %s", positions.Positions[0].Long.AveragePrice)
+	}
+	if positions.Positions[1].Instrument != "USD_JPY" || positions.Positions[1].PL != "-3.2" {
+		t.Errorf("Unexpected closed position: %+v", positions.Positions[1])
+	}
+}
+
+func TestGetPosition(t *testing.T) {
+	defer logTestResult(t, "GetPosition")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/test-account/positions/EUR_USD" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+
+		w.Write([]byte(`{
+			"position": {"instrument": "EUR_USD", "long": {"units": "0"}, "short": {"units": "-250", "tradeIDs": ["7", "8"]}},
+			"lastTransactionID": "1000"
+		}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	position, err := c.GetPosition("EUR_USD")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if position.Position.Short.Units != "-250" {
+		t.Errorf("Expected Short.Units to be -250, got %s", position.Position.Short.Units)
+	}
+	if len(position.Position.Short.TradeIDs) != 2 {
+		t.Errorf("Expected 2 short trades, got %v", position.Position.Short.TradeIDs)
+	}
+}
+
 func TestClosePosition(t *testing.T) {
 	defer logTestResult(t, "ClosePosition")
 
@@ -116,7 +166,7 @@ func TestClosePosition(t *testing.T) {
 			return
 		}
 
-		var payload ClosePositionPayload
+		var payload map[string]json.RawMessage
 		err := json.NewDecoder(r.Body).Decode(&payload)
 		if err != nil {
 			t.Errorf("Failed to decode request body: %v", err)
@@ -124,78 +174,43 @@ func TestClosePosition(t *testing.T) {
 			return
 		}
 
-		response := ModifiedTrade{
-			LastTransactionID: "1000",
-			OrderCreateTransaction: struct {
-				Type         string    `json:"type"`
-				Instrument   string    `json:"instrument"`
-				Units        string    `json:"units"`
-				TimeInForce  string    `json:"timeInForce"`
-				PositionFill string    `json:"positionFill"`
-				Reason       string    `json:"reason"`
-				TradeClose   struct {
-					Units   string `json:"units"`
-					TradeID string `json:"tradeID"`
-				} `json:"tradeClose"`
-				ID        string    `json:"id"`
-				UserID    int       `json:"userID"`
-				AccountID string    `json:"accountID"`
-				BatchID   string    `json:"batchID"`
-				RequestID string    `json:"requestID"`
-				Time      time.Time `json:"time"`
-			}{
-				Type:       "MARKET_ORDER",
-				Instrument: "EUR_USD",
-				Units:      "-100",
-				Reason:     "POSITION_CLOSEOUT",
-				ID:         "1000",
-				AccountID:  "test-account",
-				Time:       time.Now(),
+		if string(payload["longUnits"]) != `"ALL"` {
+			t.Errorf("Expected longUnits to be ALL, got %s", payload["longUnits"])
+		}
+		if string(payload["longClientExtensions"]) != `{"tag":"strategy-1"}` {
+			t.Errorf("Unexpected longClientExtensions: %s", payload["longClientExtensions"])
+		}
+		if _, ok := payload["shortUnits"]; ok {
+			t.Errorf("Expected shortUnits to be omitted, got %s", payload["shortUnits"])
+		}
+
+		response := ClosePositionResponse{
+			LongOrderCreateTransaction: &MarketOrderTransaction{
+				TransactionBase: TransactionBase{
+					ID:        "1000",
+					Type:      "MARKET_ORDER",
+					AccountID: "test-account",
+					Time:      time.Now(),
+				},
+				Instrument:           "EUR_USD",
+				Units:                "-100",
+				Reason:               "POSITION_CLOSEOUT",
+				LongPositionCloseout: &MarketOrderCloseout{Instrument: "EUR_USD", Units: "ALL"},
 			},
-			OrderFillTransaction: struct {
-				Type           string    `json:"type"`
-				Instrument     string    `json:"instrument"`
-				Units          string    `json:"units"`
-				Price          string    `json:"price"`
-				FullPrice      FullPrice `json:"fullPrice"`
-				PL             string    `json:"pl"`
-				Financing      string    `json:"financing"`
-				Commission     string    `json:"commission"`
-				AccountBalance string    `json:"accountBalance"`
-				TradeOpened    string    `json:"tradeOpened"`
-				TimeInForce    string    `json:"timeInForce"`
-				PositionFill   string    `json:"positionFill"`
-				Reason         string    `json:"reason"`
-				TradesClosed   []struct {
-					TradeID    string `json:"tradeID"`
-					Units      string `json:"units"`
-					RealizedPL string `json:"realizedPL"`
-					Financing  string `json:"financing"`
-				} `json:"tradesClosed"`
-				TradeReduced struct {
-					TradeID    string `json:"tradeID"`
-					Units      string `json:"units"`
-					RealizedPL string `json:"realizedPL"`
-					Financing  string `json:"financing"`
-				} `json:"tradeReduced"`
-				ID            string    `json:"id"`
-				UserID        int       `json:"userID"`
-				AccountID     string    `json:"accountID"`
-				BatchID       string    `json:"batchID"`
-				RequestID     string    `json:"requestID"`
-				OrderID       string    `json:"orderId"`
-				ClientOrderID string    `json:"clientOrderId"`
-				Time          time.Time `json:"time"`
-			}{
-				Type:       "ORDER_FILL",
-				Instrument: "EUR_USD",
-				Units:      "-100",
-				Price:      "1.1000",
-				PL:         "10.00",
-				ID:         "1001",
-				AccountID:  "test-account",
-				Time:       time.Now(),
+			LongOrderFillTransaction: &OrderFillTransaction{
+				TransactionBase: TransactionBase{
+					ID:        "1001",
+					Type:      "ORDER_FILL",
+					AccountID: "test-account",
+					Time:      time.Now(),
+				},
+				Instrument:   "EUR_USD",
+				Units:        "-100",
+				Price:        "1.1000",
+				PL:           "10.00",
+				TradesClosed: []TradeReduce{{TradeID: "1", Units: "-100", RealizedPL: "10.00"}},
 			},
+			LastTransactionID: "1001",
 		}
 		json.NewEncoder(w).Encode(response)
 	}))
@@ -208,36 +223,36 @@ func TestClosePosition(t *testing.T) {
 	}
 
 	payload := ClosePositionPayload{
-		LongUnits:  "100",
-		ShortUnits: "0",
+		LongUnits:            CloseAll,
+		LongClientExtensions: &OrderExtensions{Tag: "strategy-1"},
 	}
 
-	modifiedTrade, err := c.ClosePosition("EUR_USD", payload)
+	closed, err := c.ClosePosition("EUR_USD", payload)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	if modifiedTrade.LastTransactionID != "1000" {
-		t.Errorf("Expected LastTransactionID to be 1000, got %s", modifiedTrade.LastTransactionID)
+	if closed.LastTransactionID != "1001" {
+		t.Errorf("Expected LastTransactionID to be 1001, got %s", closed.LastTransactionID)
 	}
 
-	if modifiedTrade.OrderCreateTransaction.Type != "MARKET_ORDER" {
-		t.Errorf("Expected OrderCreateTransaction.Type to be MARKET_ORDER, got %s", modifiedTrade.OrderCreateTransaction.Type)
+	if closed.LongOrderCreateTransaction == nil || closed.LongOrderCreateTransaction.Type != "MARKET_ORDER" {
+		t.Fatalf("Unexpected LongOrderCreateTransaction: %+v", closed.LongOrderCreateTransaction)
 	}
 
-	if modifiedTrade.OrderCreateTransaction.Instrument != "EUR_USD" {
-		t.Errorf("Expected OrderCreateTransaction.Instrument to be EUR_USD, got %s", modifiedTrade.OrderCreateTransaction.Instrument)
+	if closed.LongOrderCreateTransaction.Units != "-100" {
+		t.Errorf("Expected LongOrderCreateTransaction.Units to be -100, got %s", closed.LongOrderCreateTransaction.Units)
 	}
 
-	if modifiedTrade.OrderCreateTransaction.Units != "-100" {
-		t.Errorf("Expected OrderCreateTransaction.Units to be -100, got %s", modifiedTrade.OrderCreateTransaction.Units)
+	if closed.LongOrderCreateTransaction.LongPositionCloseout.Units != "ALL" {
+		t.Errorf("Expected the long position closeout to be ALL, got %s", closed.LongOrderCreateTransaction.LongPositionCloseout.Units)
 	}
 
-	if modifiedTrade.OrderFillTransaction.Type != "ORDER_FILL" {
-		t.Errorf("Expected OrderFillTransaction.Type to be ORDER_FILL, got %s", modifiedTrade.OrderFillTransaction.Type)
+	if closed.LongOrderFillTransaction == nil || closed.LongOrderFillTransaction.Price != "1.1000" {
+		t.Errorf("Unexpected LongOrderFillTransaction: %+v", closed.LongOrderFillTransaction)
 	}
 
-	if modifiedTrade.OrderFillTransaction.Price != "1.1000" {
-		t.Errorf("Expected OrderFillTransaction.Price to be 1.1000, got %s", modifiedTrade.OrderFillTransaction.Price)
+	if closed.ShortOrderCreateTransaction != nil || closed.ShortOrderFillTransaction != nil {
+		t.Error("Expected no short side transactions")
 	}
 }
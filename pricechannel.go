@@ -0,0 +1,226 @@
+package goanda
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultPriceBuffer = 64
+
+// OverflowPolicy decides what a PriceChannel does with a price when the reader has
+// fallen behind and the channel's buffer is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the reader, which stops the stream being read. OANDA
+	// drops streams that are read too slowly.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered price to make room
+	OverflowDropOldest
+	// OverflowConflate keeps only the latest price of each instrument, replacing a price
+	// not yet read with the newer one
+	OverflowConflate
+)
+
+// PriceChannelConfig configures a PriceChannel
+// Defaults;
+//
+//	Buffer		= 64
+//	Overflow	= OverflowBlock
+//
+// With OverflowConflate the buffer holds one price per instrument and Buffer is not used.
+// Supervisor, if set, reconnects the stream as SupervisePrices does.
+type PriceChannelConfig struct {
+	Buffer     int
+	Overflow   OverflowPolicy
+	Supervisor *SupervisorConfig
+}
+
+// PriceChannel delivers a price stream on a channel, so a slow reader never stalls the
+// reading of the stream unless it asks to with OverflowBlock
+type PriceChannel struct {
+	prices  chan PricingStreamResponse
+	dropped uint64
+	err     error
+}
+
+// StreamPricesChannel streams prices of the instruments to the returned PriceChannel
+// until the context is cancelled or the stream ends. The channel is closed when the stream
+// ends, after which Err reports why.
+func (sc *StreamingConnection) StreamPricesChannel(ctx context.Context, instruments []string, config PriceChannelConfig) *PriceChannel {
+	buffer := config.Buffer
+	if buffer <= 0 {
+		buffer = defaultPriceBuffer
+	}
+
+	pc := &PriceChannel{}
+	run := func(send func(PricingStreamResponse)) error {
+		if config.Supervisor != nil {
+			return sc.SupervisePrices(ctx, instruments, *config.Supervisor, send)
+		}
+		return sc.StreamPricesContext(ctx, instruments, send)
+	}
+
+	switch config.Overflow {
+	case OverflowConflate:
+		pc.prices = make(chan PricingStreamResponse)
+		q := newConflateQueue()
+		go func() {
+			pumped := make(chan struct{})
+			go func() {
+				q.pump(ctx, pc.prices)
+				close(pumped)
+			}()
+
+			pc.err = run(func(price PricingStreamResponse) {
+				if q.push(price) {
+					atomic.AddUint64(&pc.dropped, 1)
+				}
+			})
+			q.close()
+			<-pumped
+			close(pc.prices)
+		}()
+
+	case OverflowDropOldest:
+		pc.prices = make(chan PricingStreamResponse, buffer)
+		go func() {
+			pc.err = run(func(price PricingStreamResponse) {
+				for {
+					select {
+					case pc.prices <- price:
+						return
+					default:
+					}
+					// The stream is the only sender, so the buffer is full
+					select {
+					case <-pc.prices:
+						atomic.AddUint64(&pc.dropped, 1)
+					default:
+					}
+				}
+			})
+			close(pc.prices)
+		}()
+
+	default:
+		pc.prices = make(chan PricingStreamResponse, buffer)
+		go func() {
+			pc.err = run(func(price PricingStreamResponse) {
+				select {
+				case pc.prices <- price:
+				case <-ctx.Done():
+				}
+			})
+			close(pc.prices)
+		}()
+	}
+
+	return pc
+}
+
+// Prices returns the channel prices are delivered on
+func (pc *PriceChannel) Prices() <-chan PricingStreamResponse {
+	return pc.prices
+}
+
+// Dropped returns the number of prices discarded because the reader had fallen behind.
+// With OverflowConflate it counts the prices replaced by a newer price.
+func (pc *PriceChannel) Dropped() uint64 {
+	return atomic.LoadUint64(&pc.dropped)
+}
+
+// Err returns why the stream ended. It must only be called once the channel is closed,
+// and returns the context's error if the context was cancelled.
+func (pc *PriceChannel) Err() error {
+	return pc.err
+}
+
+// conflateQueue holds the latest unread price of each instrument, in the order the
+// instruments were first queued
+type conflateQueue struct {
+	mu      sync.Mutex
+	latest  map[string]PricingStreamResponse
+	order   []string
+	closed  bool
+	pending chan struct{}
+}
+
+func newConflateQueue() *conflateQueue {
+	return &conflateQueue{
+		latest:  make(map[string]PricingStreamResponse),
+		pending: make(chan struct{}, 1),
+	}
+}
+
+// push queues the price, and reports whether it replaced an unread price
+func (q *conflateQueue) push(price PricingStreamResponse) bool {
+	q.mu.Lock()
+	_, replaced := q.latest[price.Instrument]
+	if !replaced {
+		q.order = append(q.order, price.Instrument)
+	}
+	q.latest[price.Instrument] = price
+	q.mu.Unlock()
+
+	q.notify()
+	return replaced
+}
+
+// close marks the end of the stream. The prices still queued are delivered before the
+// channel is closed.
+func (q *conflateQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	q.notify()
+}
+
+func (q *conflateQueue) notify() {
+	select {
+	case q.pending <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes the next price off the queue
+func (q *conflateQueue) pop() (price PricingStreamResponse, ok bool, closed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.order) == 0 {
+		return PricingStreamResponse{}, false, q.closed
+	}
+	instrument := q.order[0]
+	q.order = q.order[1:]
+	price = q.latest[instrument]
+	delete(q.latest, instrument)
+	return price, true, false
+}
+
+// pump sends queued prices to out until the queue is closed and empty, or the context
+// is cancelled
+func (q *conflateQueue) pump(ctx context.Context, out chan<- PricingStreamResponse) {
+	for {
+		price, ok, closed := q.pop()
+		if closed {
+			return
+		}
+		if !ok {
+			select {
+			case <-q.pending:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case out <- price:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
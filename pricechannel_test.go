@@ -0,0 +1,148 @@
+package goanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newPriceChannelServer streams the prices, one per instrument and time, then closes the
+// stream once release is closed
+func newPriceChannelServer(prices [][2]string, release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range prices {
+			fmt.Fprintf(w, `{"type":"PRICE","instrument":"%s","time":"%s"}`+"\n", p[0], p[1])
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+}
+
+func TestStreamPricesChannelBlock(t *testing.T) {
+	defer logTestResult(t, "StreamPricesChannelBlock")
+
+	release := make(chan struct{})
+	close(release)
+	server := newPriceChannelServer([][2]string{{"EUR_USD", "1"}, {"EUR_USD", "2"}, {"EUR_USD", "3"}}, release)
+	defer server.Close()
+
+	pc := newSupervisorTestConnection(server).StreamPricesChannel(context.Background(), []string{"EUR_USD"}, PriceChannelConfig{Buffer: 1})
+
+	var times []string
+	for price := range pc.Prices() {
+		times = append(times, price.Time)
+	}
+	if fmt.Sprint(times) != "[1 2 3]" {
+		t.Errorf("Expected every price, got %v", times)
+	}
+	if pc.Dropped() != 0 {
+		t.Errorf("Expected no prices dropped, got %d", pc.Dropped())
+	}
+	if pc.Err() != nil {
+		t.Errorf("Unexpected error: %v", pc.Err())
+	}
+}
+
+func TestStreamPricesChannelDropOldest(t *testing.T) {
+	defer logTestResult(t, "StreamPricesChannelDropOldest")
+
+	var prices [][2]string
+	for i := 1; i <= 10; i++ {
+		prices = append(prices, [2]string{"EUR_USD", fmt.Sprint(i)})
+	}
+	release := make(chan struct{})
+	close(release)
+	server := newPriceChannelServer(prices, release)
+	defer server.Close()
+
+	pc := newSupervisorTestConnection(server).StreamPricesChannel(context.Background(), []string{"EUR_USD"}, PriceChannelConfig{
+		Buffer:   3,
+		Overflow: OverflowDropOldest,
+	})
+
+	// Fall behind until the stream has ended
+	deadline := time.Now().Add(2 * time.Second)
+	for pc.Dropped() < 7 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	var times []string
+	for price := range pc.Prices() {
+		times = append(times, price.Time)
+	}
+	if fmt.Sprint(times) != "[8 9 10]" {
+		t.Errorf("Expected the latest 3 prices, got %v", times)
+	}
+	if pc.Dropped() != 7 {
+		t.Errorf("Expected 7 prices dropped, got %d", pc.Dropped())
+	}
+}
+
+func TestStreamPricesChannelConflate(t *testing.T) {
+	defer logTestResult(t, "StreamPricesChannelConflate")
+
+	prices := [][2]string{
+		{"EUR_USD", "1"}, {"USD_JPY", "1"}, {"EUR_USD", "2"}, {"EUR_USD", "3"}, {"USD_JPY", "2"},
+	}
+	release := make(chan struct{})
+	server := newPriceChannelServer(prices, release)
+	defer server.Close()
+
+	pc := newSupervisorTestConnection(server).StreamPricesChannel(context.Background(), []string{"EUR_USD", "USD_JPY"}, PriceChannelConfig{
+		Overflow: OverflowConflate,
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	latest := make(map[string]string)
+	received := 0
+	for price := range pc.Prices() {
+		if price.Time <= latest[price.Instrument] {
+			t.Errorf("Received %s price %s after %s", price.Instrument, price.Time, latest[price.Instrument])
+		}
+		latest[price.Instrument] = price.Time
+		received++
+	}
+
+	if latest["EUR_USD"] != "3" || latest["USD_JPY"] != "2" {
+		t.Errorf("Expected the latest price of each instrument to be delivered, got %v", latest)
+	}
+	if uint64(received)+pc.Dropped() != uint64(len(prices)) {
+		t.Errorf("Expected %d prices received or dropped, got %d and %d", len(prices), received, pc.Dropped())
+	}
+}
+
+func TestStreamPricesChannelCancel(t *testing.T) {
+	defer logTestResult(t, "StreamPricesChannelCancel")
+
+	server := newPriceChannelServer([][2]string{{"EUR_USD", "1"}}, make(chan struct{}))
+	defer server.Close()
+
+	for _, policy := range []OverflowPolicy{OverflowBlock, OverflowDropOldest, OverflowConflate} {
+		ctx, cancel := context.WithCancel(context.Background())
+		pc := newSupervisorTestConnection(server).StreamPricesChannel(ctx, []string{"EUR_USD"}, PriceChannelConfig{Overflow: policy})
+
+		<-pc.Prices()
+		cancel()
+
+		select {
+		case _, open := <-pc.Prices():
+			if open {
+				t.Errorf("Policy %d: unexpected price after cancelling", policy)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Policy %d: channel not closed after cancelling", policy)
+		}
+		if !errors.Is(pc.Err(), context.Canceled) {
+			t.Errorf("Policy %d: expected context.Canceled, got %v", policy, pc.Err())
+		}
+	}
+}
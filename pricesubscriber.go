@@ -0,0 +1,323 @@
+package goanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSubscribeDebounce    = time.Millisecond * 250
+	defaultInstrumentsPerStream = 50
+	defaultMaxPriceStreams      = 10
+)
+
+// PriceSubscriberConfig configures a PriceSubscriber
+// Defaults;
+//
+//	Debounce		= 250 milliseconds
+//	InstrumentsPerStream	= 50
+//	MaxStreams		= 10
+//
+// Changes to the subscribed instruments are applied once none have been made for
+// Debounce, so a burst of changes reconnects the streams once. Instruments are spread
+// over as many streams as needed to stream at most InstrumentsPerStream instruments each,
+// keeping the URLs short, up to MaxStreams streams, which must stay within the number of
+// concurrent connections OANDA allows. Each stream is supervised with Supervisor.
+type PriceSubscriberConfig struct {
+	Debounce             time.Duration
+	InstrumentsPerStream int
+	MaxStreams           int
+	Supervisor           SupervisorConfig
+}
+
+// PriceSubscriber streams prices of a set of instruments that can change at runtime, and
+// passes each price to the listeners subscribed to its instrument. A subscriber is safe
+// for concurrent use.
+//
+// Listeners of instruments streamed on different streams may be called concurrently;
+// the listeners of an instrument are called in turn, on the goroutine of its stream.
+type PriceSubscriber struct {
+	sc                   *StreamingConnection
+	debounce             time.Duration
+	instrumentsPerStream int
+	maxStreams           int
+	supervisor           SupervisorConfig
+
+	mu        sync.RWMutex
+	listeners map[string][]*Subscription
+	changed   chan struct{}
+}
+
+// Subscription is a listener subscribed to the prices of some instruments
+type Subscription struct {
+	instruments []string
+	listener    func(PricingStreamResponse)
+}
+
+// Instruments returns the instruments the subscription listens to
+func (s *Subscription) Instruments() []string {
+	return append([]string(nil), s.instruments...)
+}
+
+// NewPriceSubscriber returns a PriceSubscriber streaming through the connection.
+// Prices are only streamed while Run is running.
+func (sc *StreamingConnection) NewPriceSubscriber(config PriceSubscriberConfig) *PriceSubscriber {
+	ps := &PriceSubscriber{
+		sc:                   sc,
+		debounce:             config.Debounce,
+		instrumentsPerStream: config.InstrumentsPerStream,
+		maxStreams:           config.MaxStreams,
+		supervisor:           config.Supervisor,
+		listeners:            make(map[string][]*Subscription),
+		changed:              make(chan struct{}, 1),
+	}
+	if ps.debounce <= 0 {
+		ps.debounce = defaultSubscribeDebounce
+	}
+	if ps.instrumentsPerStream <= 0 {
+		ps.instrumentsPerStream = defaultInstrumentsPerStream
+	}
+	if ps.maxStreams <= 0 {
+		ps.maxStreams = defaultMaxPriceStreams
+	}
+	return ps
+}
+
+// Subscribe passes the prices of the instruments to the listener until the subscription
+// is unsubscribed. The instruments are checked against those the account can trade, which
+// are fetched on first use and cached, so a misspelt instrument is refused here rather
+// than breaking the stream it would have joined.
+func (ps *PriceSubscriber) Subscribe(ctx context.Context, listener func(PricingStreamResponse), instruments ...string) (*Subscription, error) {
+	instruments = uniqueStrings(instruments)
+	for _, name := range instruments {
+		if _, err := ps.sc.lookupInstrument(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &Subscription{instruments: instruments, listener: listener}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	added := 0
+	for _, name := range instruments {
+		if len(ps.listeners[name]) == 0 {
+			added++
+		}
+	}
+	if max := ps.instrumentsPerStream * ps.maxStreams; len(ps.listeners)+added > max {
+		return nil, fmt.Errorf("goanda: subscribing to %d instruments would exceed the limit of %d", len(ps.listeners)+added, max)
+	}
+
+	for _, name := range instruments {
+		ps.listeners[name] = append(ps.listeners[name], sub)
+	}
+	ps.notify()
+	return sub, nil
+}
+
+// Unsubscribe stops passing prices to the subscription's listener. Instruments no other
+// subscription listens to are dropped from the streams.
+func (ps *PriceSubscriber) Unsubscribe(sub *Subscription) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for _, name := range sub.instruments {
+		subs := ps.listeners[name]
+		for i, s := range subs {
+			if s == sub {
+				subs = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(subs) == 0 {
+			delete(ps.listeners, name)
+		} else {
+			ps.listeners[name] = subs
+		}
+	}
+	ps.notify()
+}
+
+// Instruments returns the instruments subscribed to, sorted by name
+func (ps *PriceSubscriber) Instruments() []string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	instruments := make([]string, 0, len(ps.listeners))
+	for name := range ps.listeners {
+		instruments = append(instruments, name)
+	}
+	sort.Strings(instruments)
+	return instruments
+}
+
+func (ps *PriceSubscriber) notify() {
+	select {
+	case ps.changed <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch passes a price to the listeners of its instrument
+func (ps *PriceSubscriber) dispatch(price PricingStreamResponse) {
+	ps.mu.RLock()
+	subs := ps.listeners[price.Instrument]
+	ps.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.listener(price)
+	}
+}
+
+// subscriberStream is one of the streams run by a PriceSubscriber
+type subscriberStream struct {
+	instruments []string
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+func (s *subscriberStream) stop() {
+	s.cancel()
+	<-s.done
+}
+
+// Run streams the subscribed instruments until the context is cancelled or a stream fails
+// permanently, reconnecting the streams affected by every change to the subscriptions.
+// It returns the context's error or the stream's error.
+func (ps *PriceSubscriber) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	var streams []*subscriberStream
+	defer func() {
+		for _, s := range streams {
+			s.stop()
+		}
+	}()
+
+	start := func(instruments []string) *subscriberStream {
+		streamCtx, cancel := context.WithCancel(ctx)
+		s := &subscriberStream{instruments: instruments, cancel: cancel, done: make(chan struct{})}
+		go func() {
+			defer close(s.done)
+			err := ps.sc.SupervisePrices(streamCtx, instruments, ps.supervisor, ps.dispatch)
+			if streamCtx.Err() == nil {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+		}()
+		return s
+	}
+
+	timer := time.NewTimer(ps.debounce)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case <-ps.changed:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(ps.debounce)
+		case <-timer.C:
+			streams = ps.rebalance(streams, start)
+		}
+	}
+}
+
+// rebalance brings the streams in line with the subscribed instruments. Instruments stay
+// on the stream they are on, new instruments fill the streams with room first, and only
+// the streams whose instruments changed are reconnected.
+func (ps *PriceSubscriber) rebalance(streams []*subscriberStream, start func([]string) *subscriberStream) []*subscriberStream {
+	wanted := make(map[string]bool)
+	for _, name := range ps.Instruments() {
+		wanted[name] = true
+	}
+
+	sets := make([][]string, len(streams))
+	for i, s := range streams {
+		for _, name := range s.instruments {
+			if wanted[name] {
+				sets[i] = append(sets[i], name)
+				delete(wanted, name)
+			}
+		}
+	}
+
+	var added []string
+	for name := range wanted {
+		added = append(added, name)
+	}
+	sort.Strings(added)
+
+	for i := range sets {
+		for len(added) > 0 && len(sets[i]) < ps.instrumentsPerStream {
+			sets[i] = append(sets[i], added[0])
+			added = added[1:]
+		}
+	}
+	for len(added) > 0 {
+		n := ps.instrumentsPerStream
+		if n > len(added) {
+			n = len(added)
+		}
+		sets = append(sets, added[:n:n])
+		added = added[n:]
+	}
+
+	var next []*subscriberStream
+	for i, set := range sets {
+		sort.Strings(set)
+		if i < len(streams) {
+			if equalStrings(streams[i].instruments, set) {
+				next = append(next, streams[i])
+				continue
+			}
+			streams[i].stop()
+		}
+		if len(set) > 0 {
+			next = append(next, start(set))
+		}
+	}
+	return next
+}
+
+// uniqueStrings returns a copy of s without repeated strings, in the order they first appear
+func uniqueStrings(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	unique := make([]string, 0, len(s))
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+package goanda
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// priceSubscriberServer streams one price for each instrument requested, then holds the
+// stream open, recording the instruments of the streams currently open
+type priceSubscriberServer struct {
+	*httptest.Server
+
+	mu   sync.Mutex
+	open map[string]int
+}
+
+func newPriceSubscriberServer() *priceSubscriberServer {
+	s := &priceSubscriberServer{open: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		instruments := r.URL.Query().Get("instruments")
+		s.mu.Lock()
+		s.open[instruments]++
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			if s.open[instruments]--; s.open[instruments] == 0 {
+				delete(s.open, instruments)
+			}
+			s.mu.Unlock()
+		}()
+
+		for _, name := range strings.Split(instruments, ",") {
+			fmt.Fprintf(w, `{"type":"PRICE","instrument":"%s"}`+"\n", name)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	return s
+}
+
+// waitForStreams waits until the streams open are those given
+func (s *priceSubscriberServer) waitForStreams(t *testing.T, want ...string) {
+	t.Helper()
+	sort.Strings(want)
+
+	var got []string
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		got = got[:0]
+		for instruments := range s.open {
+			got = append(got, instruments)
+		}
+		s.mu.Unlock()
+		sort.Strings(got)
+
+		if fmt.Sprint(got) == fmt.Sprint(want) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected streams %v, got %v", want, got)
+}
+
+func newPriceSubscriberTestConnection(server *priceSubscriberServer, names ...string) *StreamingConnection {
+	sc := newSupervisorTestConnection(server.Server)
+	var instruments Instruments
+	for _, name := range names {
+		instruments = append(instruments, Instrument{Name: name})
+	}
+	sc.cacheInstruments(instruments)
+	return sc
+}
+
+func TestPriceSubscriber(t *testing.T) {
+	defer logTestResult(t, "PriceSubscriber")
+
+	server := newPriceSubscriberServer()
+	defer server.Close()
+
+	sc := newPriceSubscriberTestConnection(server, "EUR_USD", "USD_JPY", "GBP_USD")
+	ps := sc.NewPriceSubscriber(PriceSubscriberConfig{Debounce: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- ps.Run(ctx)
+	}()
+
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	listener := func(name string) func(PricingStreamResponse) {
+		return func(price PricingStreamResponse) {
+			mu.Lock()
+			received[name] = append(received[name], price.Instrument)
+			mu.Unlock()
+		}
+	}
+
+	euro, err := ps.Subscribe(ctx, listener("euro"), "EUR_USD")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ps.Subscribe(ctx, listener("yen"), "USD_JPY", "EUR_USD"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.waitForStreams(t, "EUR_USD,USD_JPY")
+
+	if _, err := ps.Subscribe(ctx, listener("cable"), "GBP_USD"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.waitForStreams(t, "EUR_USD,GBP_USD,USD_JPY")
+
+	// EUR_USD is still wanted by the second subscription
+	ps.Unsubscribe(euro)
+	time.Sleep(50 * time.Millisecond)
+	server.waitForStreams(t, "EUR_USD,GBP_USD,USD_JPY")
+	if got := ps.Instruments(); fmt.Sprint(got) != "[EUR_USD GBP_USD USD_JPY]" {
+		t.Errorf("Unexpected instruments: %v", got)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	server.waitForStreams(t)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, price := range received["euro"] {
+		if price != "EUR_USD" {
+			t.Errorf("Euro listener received a price for %s", price)
+		}
+	}
+	for _, price := range received["cable"] {
+		if price != "GBP_USD" {
+			t.Errorf("Cable listener received a price for %s", price)
+		}
+	}
+	if len(received["euro"]) == 0 || len(received["yen"]) == 0 || len(received["cable"]) == 0 {
+		t.Errorf("Expected every listener to receive prices, got %v", received)
+	}
+}
+
+func TestPriceSubscriberSplitsStreams(t *testing.T) {
+	defer logTestResult(t, "PriceSubscriberSplitsStreams")
+
+	server := newPriceSubscriberServer()
+	defer server.Close()
+
+	sc := newPriceSubscriberTestConnection(server, "A", "B", "C", "D", "E")
+	ps := sc.NewPriceSubscriber(PriceSubscriberConfig{
+		Debounce:             10 * time.Millisecond,
+		InstrumentsPerStream: 2,
+		MaxStreams:           3,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ps.Run(ctx)
+
+	var subs []*Subscription
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		sub, err := ps.Subscribe(ctx, func(PricingStreamResponse) {}, name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		subs = append(subs, sub)
+	}
+	server.waitForStreams(t, "A,B", "C,D", "E")
+
+	// Only the stream losing an instrument is reconnected
+	ps.Unsubscribe(subs[2])
+	server.waitForStreams(t, "A,B", "D", "E")
+
+	// New instruments fill the streams with room first
+	if _, err := ps.Subscribe(ctx, func(PricingStreamResponse) {}, "C"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.waitForStreams(t, "A,B", "C,D", "E")
+}
+
+func TestPriceSubscriberRefusesInstruments(t *testing.T) {
+	defer logTestResult(t, "PriceSubscriberRefusesInstruments")
+
+	server := newPriceSubscriberServer()
+	defer server.Close()
+
+	sc := newPriceSubscriberTestConnection(server, "EUR_USD", "USD_JPY")
+	ps := sc.NewPriceSubscriber(PriceSubscriberConfig{InstrumentsPerStream: 1, MaxStreams: 1})
+
+	if _, err := ps.Subscribe(context.Background(), func(PricingStreamResponse) {}, "EUR_UDS"); err == nil {
+		t.Error("Expected an unknown instrument to be refused")
+	}
+	if _, err := ps.Subscribe(context.Background(), func(PricingStreamResponse) {}, "EUR_USD"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ps.Subscribe(context.Background(), func(PricingStreamResponse) {}, "USD_JPY"); err == nil {
+		t.Error("Expected subscribing beyond the stream limit to be refused")
+	}
+	if got := ps.Instruments(); fmt.Sprint(got) != "[EUR_USD]" {
+		t.Errorf("Expected only EUR_USD to be subscribed, got %v", got)
+	}
+}
+
+func TestPriceSubscriberDuplicateInstruments(t *testing.T) {
+	defer logTestResult(t, "PriceSubscriberDuplicateInstruments")
+
+	server := newPriceSubscriberServer()
+	defer server.Close()
+
+	sc := newPriceSubscriberTestConnection(server, "EUR_USD")
+	ps := sc.NewPriceSubscriber(PriceSubscriberConfig{InstrumentsPerStream: 1, MaxStreams: 1})
+
+	// A repeated instrument counts once towards the limit and registers the listener once
+	sub, err := ps.Subscribe(context.Background(), func(PricingStreamResponse) {}, "EUR_USD", "EUR_USD")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := sub.Instruments(); fmt.Sprint(got) != "[EUR_USD]" {
+		t.Errorf("Expected the subscription to list EUR_USD once, got %v", got)
+	}
+	if n := len(ps.listeners["EUR_USD"]); n != 1 {
+		t.Errorf("Expected 1 listener of EUR_USD, got %d", n)
+	}
+
+	ps.Unsubscribe(sub)
+	if got := ps.Instruments(); len(got) != 0 {
+		t.Errorf("Expected no instruments after unsubscribing, got %v", got)
+	}
+}
@@ -1,6 +1,7 @@
 package goanda
 
 import (
+	"context"
 	"net/url"
 	"strings"
 	"time"
@@ -11,46 +12,52 @@ import (
 type Pricings struct {
 	Prices []struct {
 		Asks []struct {
-			Liquidity int    `json:"liquidity"`
-			Price     string `json:"price"`
+			Liquidity int     `json:"liquidity"`
+			Price     Decimal `json:"price"`
 		} `json:"asks"`
 		Bids []struct {
-			Liquidity int    `json:"liquidity"`
-			Price     string `json:"price"`
+			Liquidity int     `json:"liquidity"`
+			Price     Decimal `json:"price"`
 		} `json:"bids"`
-		CloseoutAsk                string `json:"closeoutAsk"`
-		CloseoutBid                string `json:"closeoutBid"`
-		Instrument                 string `json:"instrument"`
+		CloseoutAsk                Decimal `json:"closeoutAsk"`
+		CloseoutBid                Decimal `json:"closeoutBid"`
+		Instrument                 string  `json:"instrument"`
 		QuoteHomeConversionFactors struct {
-			NegativeUnits string `json:"negativeUnits"`
-			PositiveUnits string `json:"positiveUnits"`
+			NegativeUnits Decimal `json:"negativeUnits"`
+			PositiveUnits Decimal `json:"positiveUnits"`
 		} `json:"quoteHomeConversionFactors"`
 		Status         string    `json:"status"`
 		Time           time.Time `json:"time"`
 		UnitsAvailable struct {
 			Default struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"default"`
 			OpenOnly struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"openOnly"`
 			ReduceFirst struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"reduceFirst"`
 			ReduceOnly struct {
-				Long  string `json:"long"`
-				Short string `json:"short"`
+				Long  Decimal `json:"long"`
+				Short Decimal `json:"short"`
 			} `json:"reduceOnly"`
 		} `json:"unitsAvailable"`
 	} `json:"prices"`
 }
 
 func (c *Connection) GetPricingForInstruments(instruments []string) (Pricings, error) {
+	return c.GetPricingForInstrumentsContext(context.Background(), instruments)
+}
+
+// GetPricingForInstrumentsContext is GetPricingForInstruments bound to the given context
+func (c *Connection) GetPricingForInstrumentsContext(ctx context.Context, instruments []string) (Pricings, error) {
 	pr := Pricings{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/pricing?instruments="+
@@ -28,51 +28,51 @@ func TestGetPricingForInstruments(t *testing.T) {
 		response := Pricings{
 			Prices: []struct {
 				Asks []struct {
-					Liquidity int    `json:"liquidity"`
-					Price     string `json:"price"`
+					Liquidity int     `json:"liquidity"`
+					Price     Decimal `json:"price"`
 				} `json:"asks"`
 				Bids []struct {
-					Liquidity int    `json:"liquidity"`
-					Price     string `json:"price"`
+					Liquidity int     `json:"liquidity"`
+					Price     Decimal `json:"price"`
 				} `json:"bids"`
-				CloseoutAsk                string `json:"closeoutAsk"`
-				CloseoutBid                string `json:"closeoutBid"`
-				Instrument                 string `json:"instrument"`
+				CloseoutAsk                Decimal `json:"closeoutAsk"`
+				CloseoutBid                Decimal `json:"closeoutBid"`
+				Instrument                 string  `json:"instrument"`
 				QuoteHomeConversionFactors struct {
-					NegativeUnits string `json:"negativeUnits"`
-					PositiveUnits string `json:"positiveUnits"`
+					NegativeUnits Decimal `json:"negativeUnits"`
+					PositiveUnits Decimal `json:"positiveUnits"`
 				} `json:"quoteHomeConversionFactors"`
 				Status         string    `json:"status"`
 				Time           time.Time `json:"time"`
 				UnitsAvailable struct {
 					Default struct {
-						Long  string `json:"long"`
-						Short string `json:"short"`
+						Long  Decimal `json:"long"`
+						Short Decimal `json:"short"`
 					} `json:"default"`
 					OpenOnly struct {
-						Long  string `json:"long"`
-						Short string `json:"short"`
+						Long  Decimal `json:"long"`
+						Short Decimal `json:"short"`
 					} `json:"openOnly"`
 					ReduceFirst struct {
-						Long  string `json:"long"`
-						Short string `json:"short"`
+						Long  Decimal `json:"long"`
+						Short Decimal `json:"short"`
 					} `json:"reduceFirst"`
 					ReduceOnly struct {
-						Long  string `json:"long"`
-						Short string `json:"short"`
+						Long  Decimal `json:"long"`
+						Short Decimal `json:"short"`
 					} `json:"reduceOnly"`
 				} `json:"unitsAvailable"`
 			}{
 				{
 					Asks: []struct {
-						Liquidity int    `json:"liquidity"`
-						Price     string `json:"price"`
+						Liquidity int     `json:"liquidity"`
+						Price     Decimal `json:"price"`
 					}{
 						{Liquidity: 10000000, Price: "1.10050"},
 					},
 					Bids: []struct {
-						Liquidity int    `json:"liquidity"`
-						Price     string `json:"price"`
+						Liquidity int     `json:"liquidity"`
+						Price     Decimal `json:"price"`
 					}{
 						{Liquidity: 10000000, Price: "1.10040"},
 					},
@@ -84,14 +84,14 @@ func TestGetPricingForInstruments(t *testing.T) {
 				},
 				{
 					Asks: []struct {
-						Liquidity int    `json:"liquidity"`
-						Price     string `json:"price"`
+						Liquidity int     `json:"liquidity"`
+						Price     Decimal `json:"price"`
 					}{
 						{Liquidity: 10000000, Price: "109.500"},
 					},
 					Bids: []struct {
-						Liquidity int    `json:"liquidity"`
-						Price     string `json:"price"`
+						Liquidity int     `json:"liquidity"`
+						Price     Decimal `json:"price"`
 					}{
 						{Liquidity: 10000000, Price: "109.490"},
 					},
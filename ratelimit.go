@@ -0,0 +1,199 @@
+package goanda
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRequestsPerSecond        = 100
+	defaultHistoryRequestsPerSecond = 50
+)
+
+// RateLimitConfig configures client side throttling of requests
+// Defaults;
+//
+//	RequestsPerSecond		= 100
+//	Burst				= RequestsPerSecond
+//	HistoryRequestsPerSecond	= 50
+//	HistoryBurst			= HistoryRequestsPerSecond
+//
+// Every request takes a token from a shared bucket refilled at RequestsPerSecond.
+// History requests (candles, order and position books) must first take a token from a
+// second bucket refilled at HistoryRequestsPerSecond, so a large backfill can never use
+// the whole allowance and starve order and trade requests.
+type RateLimitConfig struct {
+	RequestsPerSecond        float64
+	Burst                    int
+	HistoryRequestsPerSecond float64
+	HistoryBurst             int
+}
+
+// RateLimitStats describes the time requests spent waiting on the rate limiter
+type RateLimitStats struct {
+	Requests  uint64
+	Throttled uint64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// RateLimiterStats holds wait statistics for each class of request
+type RateLimiterStats struct {
+	Trading RateLimitStats
+	History RateLimitStats
+}
+
+type rateLimiter struct {
+	all     *tokenBucket
+	history *tokenBucket
+
+	mu    sync.Mutex
+	stats RateLimiterStats
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	rps := config.RequestsPerSecond
+	if rps <= 0 {
+		rps = defaultRequestsPerSecond
+	}
+	historyRPS := config.HistoryRequestsPerSecond
+	if historyRPS <= 0 {
+		historyRPS = defaultHistoryRequestsPerSecond
+	}
+
+	return &rateLimiter{
+		all:     newTokenBucket(rps, config.Burst),
+		history: newTokenBucket(historyRPS, config.HistoryBurst),
+	}
+}
+
+// isHistoryEndpoint reports whether the endpoint serves bulk market data, which is
+// throttled behind order and trade requests
+func isHistoryEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "/instruments/")
+}
+
+// wait blocks until the request to endpoint may be sent
+func (rl *rateLimiter) wait(ctx context.Context, endpoint string) error {
+	history := isHistoryEndpoint(endpoint)
+
+	// The shared token is only taken once the history token is granted, so history
+	// requests queued behind their own limit never hold up other requests
+	var waited time.Duration
+	if history {
+		d, err := rl.history.wait(ctx)
+		if err != nil {
+			return err
+		}
+		waited = d
+	}
+
+	d, err := rl.all.wait(ctx)
+	if err != nil {
+		if history {
+			rl.history.cancel()
+		}
+		return err
+	}
+	waited += d
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	stats := &rl.stats.Trading
+	if history {
+		stats = &rl.stats.History
+	}
+	stats.Requests++
+	if waited > 0 {
+		stats.Throttled++
+		stats.TotalWait += waited
+		if waited > stats.MaxWait {
+			stats.MaxWait = waited
+		}
+	}
+	return nil
+}
+
+func (rl *rateLimiter) snapshot() RateLimiterStats {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.stats
+}
+
+// tokenBucket is a token bucket rate limiter. Tokens may go negative, which queues
+// callers fairly in the order they arrived.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := float64(burst)
+	if b < 1 {
+		b = rate
+	}
+	if b < 1 {
+		b = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token, returning how long the caller must wait before using it
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a token taken by reserve that was never used
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// wait blocks until a token is available, returning how long it waited
+func (b *tokenBucket) wait(ctx context.Context) (time.Duration, error) {
+	d := b.reserve(time.Now())
+	if d == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return 0, ctx.Err()
+	case <-timer.C:
+		return d, nil
+	}
+}
@@ -0,0 +1,149 @@
+package goanda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	defer logTestResult(t, "TokenBucket")
+
+	b := newTokenBucket(10, 2)
+	now := b.last
+
+	if d := b.reserve(now); d != 0 {
+		t.Errorf("Expected first token to be free, waited %v", d)
+	}
+	if d := b.reserve(now); d != 0 {
+		t.Errorf("Expected second token to be free, waited %v", d)
+	}
+	if d := b.reserve(now); d != 100*time.Millisecond {
+		t.Errorf("Expected third token to wait 100ms, waited %v", d)
+	}
+	if d := b.reserve(now); d != 200*time.Millisecond {
+		t.Errorf("Expected fourth token to queue behind the third, waited %v", d)
+	}
+
+	// A second later the bucket is full again
+	if d := b.reserve(now.Add(time.Second)); d != 0 {
+		t.Errorf("Expected refilled token to be free, waited %v", d)
+	}
+}
+
+func TestTokenBucketCancelled(t *testing.T) {
+	defer logTestResult(t, "TokenBucketCancelled")
+
+	b := newTokenBucket(1, 1)
+	b.reserve(time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := b.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if b.tokens < 0 {
+		t.Errorf("Expected cancelled wait to return its token, tokens = %v", b.tokens)
+	}
+}
+
+func TestRateLimiterCancelledReturnsTokens(t *testing.T) {
+	defer logTestResult(t, "RateLimiterCancelledReturnsTokens")
+
+	rl := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, Burst: 1, HistoryRequestsPerSecond: 1, HistoryBurst: 1})
+	// Use up the shared bucket so history requests have to wait for it
+	rl.all.reserve(time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := rl.wait(ctx, "/instruments/EUR_USD/candles"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if rl.history.tokens < 1 {
+		t.Errorf("Expected the history token to be returned, tokens = %v", rl.history.tokens)
+	}
+	if rl.all.tokens < 0 {
+		t.Errorf("Expected the shared token to be returned, tokens = %v", rl.all.tokens)
+	}
+}
+
+func TestRateLimiterHistoryBacklog(t *testing.T) {
+	defer logTestResult(t, "RateLimiterHistoryBacklog")
+
+	rl := newRateLimiter(RateLimitConfig{RequestsPerSecond: 100, HistoryRequestsPerSecond: 50})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// A backfill queues far more history requests than either bucket holds
+	for i := 0; i < 500; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rl.wait(ctx, "/instruments/EUR_USD/candles")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if err := rl.wait(context.Background(), "/accounts/test-account/orders"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if waited := time.Since(start); waited > 100*time.Millisecond {
+		t.Errorf("Expected an order request not to wait behind the history backlog, waited %v", waited)
+	}
+}
+
+func TestRateLimitHistoryRequests(t *testing.T) {
+	defer logTestResult(t, "RateLimitHistoryRequests")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(struct{}{})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+		limiter: newRateLimiter(RateLimitConfig{
+			RequestsPerSecond:        1000,
+			HistoryRequestsPerSecond: 20,
+			HistoryBurst:             1,
+		}),
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetCandles("EUR_USD", 10, GranularityMinute); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := c.GetOpenTrades(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	stats := c.RateLimitStats()
+	if stats.History.Requests != 3 || stats.Trading.Requests != 3 {
+		t.Fatalf("Expected 3 requests of each class, got %+v", stats)
+	}
+	if stats.History.Throttled != 2 {
+		t.Errorf("Expected 2 history requests to be throttled, got %d", stats.History.Throttled)
+	}
+	if stats.History.TotalWait < 50*time.Millisecond {
+		t.Errorf("Expected history requests to wait, waited %v", stats.History.TotalWait)
+	}
+	if stats.Trading.Throttled != 0 {
+		t.Errorf("Expected trading requests not to be throttled, got %d", stats.Trading.Throttled)
+	}
+}
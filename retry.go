@@ -0,0 +1,153 @@
+package goanda
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = time.Millisecond * 250
+	defaultRetryMaxDelay  = time.Second * 10
+)
+
+// RetryPolicy configures how failed requests are retried
+// Defaults;
+//
+//	MaxAttempts	= 3
+//	BaseDelay	= 250 milliseconds
+//	MaxDelay	= 10 seconds
+//
+// Requests are retried when the api responds with 429 Too Many Requests or a 5xx
+// status, or when the api couldn't be reached or its response was cut short. The delay doubles with every
+// attempt, starting at BaseDelay and capped at MaxDelay, with random jitter applied.
+// A Retry-After header sent by the api takes precedence over the computed delay, but
+// is capped at MaxDelay too.
+//
+// GET and PUT requests are eligible for retry, except for closing part of a trade or
+// position, which would close too much if repeated. Orders are only retried when
+// they carry a client order ID in OrderExtensions.ID, as the api refuses to create
+// a second order with the same ID.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultRetryAttempts
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		return transportError(err)
+	}
+
+	switch apiErr.Response.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// transportError reports whether err means the api couldn't be reached or its response
+// was cut short. Other errors, such as a request that can't be built, would only fail
+// the same way again.
+func transportError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Op != "parse"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// wait sleeps before the next attempt, returning early if ctx is done
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	timer := time.NewTimer(p.delay(attempt, err))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// delay returns how long to wait after the given (1 based) failed attempt
+func (p *RetryPolicy) delay(attempt int, err error) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	max := p.MaxDelay
+	if max <= 0 {
+		max = defaultRetryMaxDelay
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		if d, ok := retryAfter(apiErr.Response.Header.Get("Retry-After"), time.Now()); ok {
+			if d > max {
+				d = max
+			}
+			return d
+		}
+	}
+
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	// Equal jitter, so concurrent callers don't retry in lockstep
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as a http date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,305 @@
+package goanda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransientErrors(t *testing.T) {
+	defer logTestResult(t, "RetryTransientErrors")
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"errorMessage":"Too many requests"}`, http.StatusTooManyRequests)
+		case 2:
+			http.Error(w, `{"errorMessage":"Bad gateway"}`, http.StatusBadGateway)
+		default:
+			json.NewEncoder(w).Encode(ReceivedTrades{LastTransactionID: "1000"})
+		}
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+		retry:     &RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	}
+
+	trades, err := c.GetOpenTrades()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if trades.LastTransactionID != "1000" {
+		t.Errorf("Expected LastTransactionID to be 1000, got %s", trades.LastTransactionID)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	defer logTestResult(t, "RetryGivesUp")
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, `{"errorMessage":"Unavailable"}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+		retry:     &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	}
+
+	_, err := c.GetOpenTrades()
+	if _, ok := err.(APIError); !ok {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRetryNotOnClientError(t *testing.T) {
+	defer logTestResult(t, "RetryNotOnClientError")
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, `{"errorMessage":"Invalid value specified for 'instrument'"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+		retry:     &RetryPolicy{BaseDelay: time.Millisecond},
+	}
+
+	_, err := c.GetOpenTrades()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestRetryOnlyTransportErrors(t *testing.T) {
+	defer logTestResult(t, "RetryOnlyTransportErrors")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ReceivedTrades{LastTransactionID: "1000"})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+		retry:     &RetryPolicy{BaseDelay: time.Millisecond},
+	}
+
+	var calls int32
+	fail := errors.New("refused by interceptor")
+	c.Use(func(req *http.Request, next RequestFunc) (*Response, error) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: errors.New("connection reset by peer")}
+		case 2:
+			return nil, fail
+		}
+		return next(req)
+	})
+
+	// The connection failure is retried, the interceptor's own error is not
+	if _, err := c.GetOpenTrades(); !errors.Is(err, fail) {
+		t.Errorf("Expected the interceptor's error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+
+	// A request that can't be built is never retried
+	_, err := http.NewRequest(http.MethodGet, "http://[::1", nil)
+	if err == nil {
+		t.Fatal("Expected an invalid url to fail")
+	}
+	if c.retry.shouldRetry(context.Background(), err) {
+		t.Errorf("Expected %v not to be retried", err)
+	}
+}
+
+func TestRetryCreateOrder(t *testing.T) {
+	defer logTestResult(t, "RetryCreateOrder")
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			http.Error(w, `{"errorMessage":"Unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(OrderResponse{LastTransactionID: "1000"})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+		retry:     &RetryPolicy{BaseDelay: time.Millisecond},
+	}
+
+	// Without a client order ID the order must not be sent twice
+	_, err := c.CreateOrder(OrderPayload{Order: OrderBody{Instrument: "EUR_USD", Units: "100", Type: "MARKET"}})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if calls != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	_, err = c.CreateOrder(OrderPayload{Order: OrderBody{
+		Instrument:       "EUR_USD",
+		Units:            "100",
+		Type:             "MARKET",
+		ClientExtensions: &OrderExtensions{ID: "my-order-1"},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRetryPartialClose(t *testing.T) {
+	defer logTestResult(t, "RetryPartialClose")
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			http.Error(w, `{"errorMessage":"Unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"lastTransactionID": "1000"}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+		retry:     &RetryPolicy{BaseDelay: time.Millisecond},
+	}
+
+	// A partial close may already have been applied, so it must not be sent twice
+	if _, err := c.ReduceTradeSize("1", CloseTradePayload{Units: "50"}); err == nil {
+		t.Error("Expected an error closing part of a trade")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 attempt closing part of a trade, got %d", calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	if _, err := c.ClosePosition("EUR_USD", ClosePositionPayload{LongUnits: CloseUnitsOf("50")}); err == nil {
+		t.Error("Expected an error closing part of a position")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 attempt closing part of a position, got %d", calls)
+	}
+
+	// Closing everything can safely be repeated
+	atomic.StoreInt32(&calls, 0)
+	if _, err := c.ReduceTradeSize("1", CloseTradePayload{Units: CloseAll}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts closing a whole trade, got %d", calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	if _, err := c.ClosePosition("EUR_USD", ClosePositionPayload{LongUnits: CloseAll}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts closing a whole position, got %d", calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	defer logTestResult(t, "RetryAfter")
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{now.Add(2 * time.Second).Format(http.TimeFormat), 2 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryDelayBackoff(t *testing.T) {
+	defer logTestResult(t, "RetryDelayBackoff")
+
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, max := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		d := p.delay(attempt, nil)
+		if d < max/2 || d > max {
+			t.Errorf("Expected delay for attempt %d to be within [%v, %v], got %v", attempt, max/2, max, d)
+		}
+	}
+}
+
+func TestRetryDelayRetryAfterCapped(t *testing.T) {
+	defer logTestResult(t, "RetryDelayRetryAfterCapped")
+
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	apiErr := func(header string) APIError {
+		return APIError{Response: &http.Response{Header: http.Header{"Retry-After": {header}}}}
+	}
+
+	if d := p.delay(1, apiErr("0")); d != 0 {
+		t.Errorf("Expected Retry-After of 0 to be used, got %v", d)
+	}
+	if d := p.delay(1, apiErr("3600")); d != time.Second {
+		t.Errorf("Expected Retry-After of an hour to be capped at 1s, got %v", d)
+	}
+}
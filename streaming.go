@@ -7,18 +7,24 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-
+	"sync"
 )
 
 type StreamingConnection struct {
 	*Connection
 	streamURL string
+
+	heartbeatMu sync.RWMutex
+	heartbeat   func(HeartbeatResponse)
 }
 
 func NewStreamingConnection(c *Connection) *StreamingConnection {
-	streamURL := "https://stream-fxpractice.oanda.com/v3"
-	if strings.Contains(c.hostname, "fxtrade") {
-		streamURL = "https://stream-fxtrade.oanda.com/v3"
+	streamURL := c.streamURL
+	if streamURL == "" {
+		streamURL = practiceStreamURL
+		if strings.Contains(c.hostname, "fxtrade") {
+			streamURL = liveStreamURL
+		}
 	}
 
 	return &StreamingConnection{
@@ -31,11 +37,38 @@ func (c *Connection) NewStreamingConnection() *StreamingConnection {
 	return NewStreamingConnection(c)
 }
 
+// OnHeartbeat registers a callback for the heartbeats of every stream opened through the
+// connection. OANDA sends a heartbeat every 5 seconds on an idle stream; heartbeats of
+// the transaction stream carry the ID of the account's last transaction. Use a streaming
+// connection per stream to tell their heartbeats apart. A nil callback ignores heartbeats,
+// which is the default.
+//
+// The callback is called on the goroutine reading each stream, so with several streams
+// open, such as those of a PriceSubscriber, it is called concurrently and must be safe
+// for concurrent use.
+func (sc *StreamingConnection) OnHeartbeat(callback func(HeartbeatResponse)) {
+	sc.heartbeatMu.Lock()
+	defer sc.heartbeatMu.Unlock()
+	sc.heartbeat = callback
+}
+
+// StreamPrices streams prices of the instruments to the callback until the server closes
+// the stream or a message can't be decoded
 func (sc *StreamingConnection) StreamPrices(instruments []string, callback func(PricingStreamResponse)) error {
+	return sc.StreamPricesContext(context.Background(), instruments, callback)
+}
+
+// StreamPricesContext is StreamPrices bound to the given context. Cancelling the context
+// closes the stream and returns the context's error.
+func (sc *StreamingConnection) StreamPricesContext(ctx context.Context, instruments []string, callback func(PricingStreamResponse)) error {
+	return unwrapMessageError(sc.streamPrices(ctx, instruments, callback, streamHooks{}))
+}
+
+func (sc *StreamingConnection) streamPrices(ctx context.Context, instruments []string, callback func(PricingStreamResponse), hooks streamHooks) error {
 	endpoint := fmt.Sprintf("/accounts/%s/pricing/stream", sc.accountID)
 	url := sc.streamURL + endpoint + "?instruments=" + strings.Join(instruments, "%2C")
 
-	return sc.stream(url, func(data []byte) error {
+	return sc.stream(ctx, url, func(data []byte) error {
 		var response PricingStreamResponse
 		err := json.Unmarshal(data, &response)
 		if err != nil {
@@ -52,28 +85,74 @@ func (sc *StreamingConnection) StreamPrices(instruments []string, callback func(
 		}
 		callback(response)
 		return nil
-	})
+	}, hooks)
 }
 
+// StreamTransactions streams the account's transactions to the callback until the server
+// closes the stream or a message can't be decoded
 func (sc *StreamingConnection) StreamTransactions(callback func(TransactionStreamResponse)) error {
+	return sc.StreamTransactionsContext(context.Background(), callback)
+}
+
+// StreamTransactionsContext is StreamTransactions bound to the given context. Cancelling
+// the context closes the stream and returns the context's error.
+func (sc *StreamingConnection) StreamTransactionsContext(ctx context.Context, callback func(TransactionStreamResponse)) error {
+	return unwrapMessageError(sc.streamTransactions(ctx, callback, streamHooks{}))
+}
+
+func (sc *StreamingConnection) streamTransactions(ctx context.Context, callback func(TransactionStreamResponse), hooks streamHooks) error {
 	endpoint := fmt.Sprintf("/accounts/%s/transactions/stream", sc.accountID)
 	url := sc.streamURL + endpoint
 
-	return sc.stream(url, func(data []byte) error {
+	return sc.stream(ctx, url, func(data []byte) error {
 		var response TransactionStreamResponse
 		err := json.Unmarshal(data, &response)
 		if err != nil {
 			return err
 		}
+		if response.Transaction.TypedTransaction == nil {
+			response.Transaction.TypedTransaction, err = DecodeTransaction(data)
+			if err != nil {
+				return err
+			}
+		}
+		if response.TransactionID == "" {
+			response.TransactionID = response.Transaction.Base().ID
+		}
 		callback(response)
 		return nil
-	})
+	}, hooks)
 }
 
-func (sc *StreamingConnection) stream(url string, handler func([]byte) error) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// messageError is returned by stream when a message can't be handled, as opposed to the
+// stream itself failing
+type messageError struct {
+	err error
+}
 
+func (e *messageError) Error() string { return e.err.Error() }
+func (e *messageError) Unwrap() error { return e.err }
+
+func unwrapMessageError(err error) error {
+	if me, ok := err.(*messageError); ok {
+		return me.err
+	}
+	return err
+}
+
+// streamHooks lets a supervisor observe a stream. Either hook may be nil.
+type streamHooks struct {
+	// connected is called once the stream is open, before any message is read. An error
+	// closes the stream.
+	connected func() error
+	// heartbeat is called for every heartbeat received
+	heartbeat func(HeartbeatResponse)
+}
+
+// stream reads the stream at url line by line, passing each message other than heartbeats
+// to the handler. Lines are read on the caller's goroutine, and cancelling the context
+// aborts the request, which unblocks the read and closes the body.
+func (sc *StreamingConnection) stream(ctx context.Context, url string, handler func([]byte) error, hooks streamHooks) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return err
@@ -82,53 +161,57 @@ func (sc *StreamingConnection) stream(url string, handler func([]byte) error) er
 	req.Header.Set("Authorization", sc.authHeader)
 	req.Header.Set("Accept-Datetime-Format", "RFC3339")
 
-	resp, err := sc.client.Do(req)
+	// The connection's timeout bounds a whole request, body included, so it would cut
+	// every stream off. A stream lasts until its context is cancelled instead.
+	client := sc.client
+	client.Timeout = 0
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
+	if resp.StatusCode >= 400 {
+		return newAPIError(req, resp)
+	}
 	defer resp.Body.Close()
 
-	done := make(chan struct{})
-	errChan := make(chan error, 1)
+	if hooks.connected != nil {
+		if err := hooks.connected(); err != nil {
+			return err
+		}
+	}
 
-	go func() {
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
-				continue
-			}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
 
-			// Handle heartbeats
-			if strings.HasPrefix(line, "{\"type\":\"HEARTBEAT\"") {
-				var heartbeat HeartbeatResponse
-				err := json.Unmarshal([]byte(line), &heartbeat)
-				if err == nil {
-					fmt.Printf("Received heartbeat at %s\n", heartbeat.Time)
-				}
-				continue
+		var heartbeat HeartbeatResponse
+		if json.Unmarshal([]byte(line), &heartbeat) == nil && heartbeat.Type == "HEARTBEAT" {
+			if hooks.heartbeat != nil {
+				hooks.heartbeat(heartbeat)
 			}
 
-			err := handler([]byte(line))
-			if err != nil {
-				errChan <- err
-				return
+			sc.heartbeatMu.RLock()
+			callback := sc.heartbeat
+			sc.heartbeatMu.RUnlock()
+			if callback != nil {
+				callback(heartbeat)
 			}
+			continue
 		}
-		if err := scanner.Err(); err != nil {
-			errChan <- err
+
+		if err := handler([]byte(line)); err != nil {
+			return &messageError{err}
 		}
-		close(done)
-	}()
+	}
 
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-errChan:
+	if err := ctx.Err(); err != nil {
 		return err
-	case <-done:
-		return nil
 	}
+	return scanner.Err()
 }
 
 type PricingStreamResponse struct {
@@ -136,30 +219,36 @@ type PricingStreamResponse struct {
 	Time       string `json:"time"`
 	Instrument string `json:"instrument,omitempty"`
 	Bids       []struct {
-		Price     string `json:"price"`
-		Liquidity int    `json:"liquidity"`
+		Price     Decimal `json:"price"`
+		Liquidity int     `json:"liquidity"`
 	} `json:"bids,omitempty"`
 	Asks []struct {
-		Price     string `json:"price"`
-		Liquidity int    `json:"liquidity"`
+		Price     Decimal `json:"price"`
+		Liquidity int     `json:"liquidity"`
 	} `json:"asks,omitempty"`
-	CloseoutBid string `json:"closeoutBid,omitempty"`
-	CloseoutAsk string `json:"closeoutAsk,omitempty"`
-	Status      string `json:"status,omitempty"`
-	Tradeable   bool   `json:"tradeable,omitempty"`
+	CloseoutBid Decimal `json:"closeoutBid,omitempty"`
+	CloseoutAsk Decimal `json:"closeoutAsk,omitempty"`
+	Status      string  `json:"status,omitempty"`
+	Tradeable   bool    `json:"tradeable,omitempty"`
 }
 
 type TransactionStreamResponse struct {
-	Type          string          `json:"type"`
-	Time          string          `json:"time"`
-	TransactionID string          `json:"transactionID,omitempty"`
-	AccountID     string          `json:"accountID,omitempty"`
-	BatchID       string          `json:"batchID,omitempty"`
-	RequestID     string          `json:"requestID,omitempty"`
-	Transaction   json.RawMessage `json:"transaction,omitempty"`
+	Type          string `json:"type"`
+	Time          string `json:"time"`
+	TransactionID string `json:"transactionID,omitempty"`
+	AccountID     string `json:"accountID,omitempty"`
+	BatchID       string `json:"batchID,omitempty"`
+	RequestID     string `json:"requestID,omitempty"`
+	// Transaction is the transaction decoded into its concrete type. The stream sends
+	// transactions unwrapped, so it is decoded from the whole message when the message
+	// has no transaction field.
+	Transaction AnyTransaction `json:"transaction,omitempty"`
 }
 
+// HeartbeatResponse is sent on an idle stream to show it is still alive. LastTransactionID
+// is only sent on the transaction stream.
 type HeartbeatResponse struct {
-	Type string `json:"type"`
-	Time string `json:"time"`
+	Type              string `json:"type"`
+	Time              string `json:"time"`
+	LastTransactionID string `json:"lastTransactionID,omitempty"`
 }
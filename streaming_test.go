@@ -1,7 +1,9 @@
 package goanda
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -52,12 +54,12 @@ func TestStreamPrices(t *testing.T) {
 			Time:       time.Now().Format(time.RFC3339),
 			Instrument: "EUR_USD",
 			Bids: []struct {
-				Price     string `json:"price"`
-				Liquidity int    `json:"liquidity"`
+				Price     Decimal `json:"price"`
+				Liquidity int     `json:"liquidity"`
 			}{{Price: "1.1000", Liquidity: 1000000}},
 			Asks: []struct {
-				Price     string `json:"price"`
-				Liquidity int    `json:"liquidity"`
+				Price     Decimal `json:"price"`
+				Liquidity int     `json:"liquidity"`
 			}{{Price: "1.1001", Liquidity: 1000000}},
 		}
 		err := json.NewEncoder(w).Encode(response)
@@ -178,8 +180,11 @@ func TestStreamHeartbeat(t *testing.T) {
 	// Override the streamURL to use the test server
 	sc.streamURL = server.URL
 
-	// This test is a bit tricky because heartbeats are handled internally.
-	// We'll use the StreamPrices function, but send a heartbeat instead.
+	var heartbeats []HeartbeatResponse
+	sc.OnHeartbeat(func(heartbeat HeartbeatResponse) {
+		heartbeats = append(heartbeats, heartbeat)
+	})
+
 	err := sc.StreamPrices([]string{"EUR_USD"}, func(response PricingStreamResponse) {
 		t.Errorf("Unexpected pricing response: %+v", response)
 	})
@@ -187,7 +192,119 @@ func TestStreamHeartbeat(t *testing.T) {
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
-	// If we reach this point without errors, it means the heartbeat was properly handled
+	if len(heartbeats) != 1 || heartbeats[0].Type != "HEARTBEAT" {
+		t.Errorf("Expected one heartbeat, got %+v", heartbeats)
+	}
+}
+
+func TestStreamTransactionHeartbeat(t *testing.T) {
+	defer logTestResult(t, "TestStreamTransactionHeartbeat")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Heartbeats are recognised by their type wherever it appears in the message
+		fmt.Fprintln(w, `{"lastTransactionID":"6","time":"2024-01-01T00:00:00Z","type":"HEARTBEAT"}`)
+		fmt.Fprintln(w, `{"type":"ORDER_FILL","id":"7","accountID":"test-account"}`)
+	}))
+	defer server.Close()
+
+	conn := &Connection{
+		hostname:   server.URL,
+		accountID:  "test-account",
+		authHeader: "Bearer test-token",
+		client:     *server.Client(),
+	}
+	sc := NewStreamingConnection(conn)
+	sc.streamURL = server.URL
+
+	var heartbeats []HeartbeatResponse
+	sc.OnHeartbeat(func(heartbeat HeartbeatResponse) {
+		heartbeats = append(heartbeats, heartbeat)
+	})
+
+	var ids []string
+	err := sc.StreamTransactions(func(response TransactionStreamResponse) {
+		ids = append(ids, response.TransactionID)
+	})
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(heartbeats) != 1 || heartbeats[0].LastTransactionID != "6" || heartbeats[0].Time != "2024-01-01T00:00:00Z" {
+		t.Errorf("Expected a heartbeat with lastTransactionID 6, got %+v", heartbeats)
+	}
+	if len(ids) != 1 || ids[0] != "7" {
+		t.Errorf("Expected transaction 7 only, got %v", ids)
+	}
+}
+
+func TestStreamPricesContextCancel(t *testing.T) {
+	defer logTestResult(t, "TestStreamPricesContextCancel")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type":"PRICE","instrument":"EUR_USD","bids":[{"price":"1.1000"}],"asks":[{"price":"1.1001"}]}`)
+		w.(http.Flusher).Flush()
+		// Hold the stream open until the client goes away
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	conn := &Connection{
+		hostname:   server.URL,
+		accountID:  "test-account",
+		authHeader: "Bearer test-token",
+		client:     *server.Client(),
+	}
+	sc := NewStreamingConnection(conn)
+	sc.streamURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- sc.StreamPricesContext(ctx, []string{"EUR_USD"}, func(response PricingStreamResponse) {
+			cancel()
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stream did not return after its context was cancelled")
+	}
+}
+
+func TestStreamIgnoresClientTimeout(t *testing.T) {
+	defer logTestResult(t, "TestStreamIgnoresClientTimeout")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(w, `{"type":"TRANSACTION","id":"%d","accountID":"test-account"}`+"\n", i+1)
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client := *server.Client()
+	client.Timeout = 30 * time.Millisecond
+	conn := &Connection{
+		hostname:   server.URL,
+		accountID:  "test-account",
+		authHeader: "Bearer test-token",
+		client:     client,
+	}
+	sc := NewStreamingConnection(conn)
+	sc.streamURL = server.URL
+
+	received := 0
+	err := sc.StreamTransactionsContext(context.Background(), func(response TransactionStreamResponse) {
+		received++
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if received != 5 {
+		t.Errorf("Expected 5 transactions, got %d", received)
+	}
 }
 
 func TestStreamingIntegration(t *testing.T) {
@@ -274,7 +391,7 @@ func TestStreamingIntegration(t *testing.T) {
 		// Create a market order to trigger a transaction
 		orderBody := OrderBody{
 			Instrument:   "EUR_USD",
-			Units:        100,
+			Units:        "100",
 			Type:         "MARKET",
 			TimeInForce:  "FOK",
 			PositionFill: "DEFAULT",
@@ -294,7 +411,7 @@ func TestStreamingIntegration(t *testing.T) {
 		}
 		// Close the order
 		_, err = conn.ReduceTradeSize(order.OrderFillTransaction.TradeOpened.TradeID, CloseTradePayload{
-			Units: "ALL",
+			Units: CloseAll,
 		})
 		if err != nil {
 			t.Fatalf("Error closing order: %v", err)
@@ -0,0 +1,240 @@
+package goanda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultHeartbeatTimeout  = time.Second * 15
+	defaultReconnectBaseWait = time.Second
+	defaultReconnectMaxWait  = time.Second * 30
+)
+
+var (
+	// ErrHeartbeatTimeout is reported when a supervised stream receives neither a message
+	// nor a heartbeat within the heartbeat timeout
+	ErrHeartbeatTimeout = errors.New("goanda: no heartbeat received within the timeout")
+	// ErrStreamClosed is reported when the server closes a supervised stream
+	ErrStreamClosed = errors.New("goanda: stream closed by the server")
+)
+
+// SupervisorConfig configures a supervised stream
+// Defaults;
+//
+//	HeartbeatTimeout	= 15 seconds
+//	BaseDelay		= 1 second
+//	MaxDelay		= 30 seconds
+//	MaxAttempts		= 0, reconnect until the context is cancelled
+//
+// OANDA sends a heartbeat every 5 seconds, so a stream that has gone HeartbeatTimeout
+// without a message or heartbeat is declared dead and reconnected. The delay before
+// reconnecting doubles with every consecutive failed attempt, from BaseDelay up to
+// MaxDelay, with random jitter applied. MaxAttempts limits the consecutive attempts
+// made before the last error is returned; it is reset once a stream delivers a message.
+//
+// OnReconnect, if set, is called on the supervisor's goroutine every time the stream is
+// lost, before waiting to reconnect.
+type SupervisorConfig struct {
+	HeartbeatTimeout time.Duration
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	MaxAttempts      int
+	OnReconnect      func(ReconnectEvent)
+}
+
+// ReconnectEvent describes a supervised stream being lost
+type ReconnectEvent struct {
+	// Attempt is the number of consecutive attempts to reconnect, starting at 1
+	Attempt int
+	// Err is why the stream was lost
+	Err error
+	// Delay is how long the supervisor waits before reconnecting
+	Delay time.Duration
+}
+
+// SupervisePrices streams prices like StreamPricesContext, reconnecting whenever the
+// stream fails, is closed by the server, or stops sending heartbeats. It only returns
+// once the context is cancelled, a message can't be decoded, the api refuses the stream,
+// or MaxAttempts consecutive attempts have failed.
+func (sc *StreamingConnection) SupervisePrices(ctx context.Context, instruments []string, config SupervisorConfig, callback func(PricingStreamResponse)) error {
+	return config.supervise(ctx, func(ctx context.Context, alive func()) error {
+		return sc.streamPrices(ctx, instruments, func(response PricingStreamResponse) {
+			alive()
+			callback(response)
+		}, streamHooks{heartbeat: func(HeartbeatResponse) { alive() }})
+	})
+}
+
+// SuperviseTransactions streams transactions like StreamTransactionsContext, reconnecting
+// like SupervisePrices. After a reconnect the transactions made while the stream was down
+// are fetched with GetTransactionsSinceId from the last transaction received, or from the
+// last transaction ID of a heartbeat if none has been, and passed to the callback before
+// the stream resumes, so none are lost or repeated.
+func (sc *StreamingConnection) SuperviseTransactions(ctx context.Context, config SupervisorConfig, callback func(TransactionStreamResponse)) error {
+	var lastID string
+
+	deliver := func(response TransactionStreamResponse) {
+		if lastID != "" && !transactionIDAfter(response.TransactionID, lastID) {
+			return
+		}
+		if response.TransactionID != "" {
+			lastID = response.TransactionID
+		}
+		callback(response)
+	}
+
+	return config.supervise(ctx, func(ctx context.Context, alive func()) error {
+		backfill := func() error {
+			if lastID == "" {
+				return nil
+			}
+			for {
+				since := lastID
+				tr, err := sc.GetTransactionsSinceIdContext(ctx, since)
+				if err != nil {
+					return err
+				}
+				if len(tr.Transactions) == 0 {
+					return nil
+				}
+				for _, t := range tr.Transactions {
+					deliver(transactionStreamResponse(t.TypedTransaction))
+				}
+				alive()
+				if lastID == since || lastID == tr.LastTransactionID {
+					return nil
+				}
+			}
+		}
+
+		return sc.streamTransactions(ctx, func(response TransactionStreamResponse) {
+			alive()
+			deliver(response)
+		}, streamHooks{
+			connected: backfill,
+			heartbeat: func(heartbeat HeartbeatResponse) {
+				alive()
+				if lastID == "" {
+					lastID = heartbeat.LastTransactionID
+				}
+			},
+		})
+	})
+}
+
+// supervise runs the stream until it fails permanently, reconnecting after every failure
+// that might be temporary. The stream calls alive for every message it receives.
+func (config SupervisorConfig) supervise(ctx context.Context, stream func(ctx context.Context, alive func()) error) error {
+	timeout := config.HeartbeatTimeout
+	if timeout <= 0 {
+		timeout = defaultHeartbeatTimeout
+	}
+	backoff := RetryPolicy{BaseDelay: config.BaseDelay, MaxDelay: config.MaxDelay}
+	if backoff.BaseDelay <= 0 {
+		backoff.BaseDelay = defaultReconnectBaseWait
+	}
+	if backoff.MaxDelay <= 0 {
+		backoff.MaxDelay = defaultReconnectMaxWait
+	}
+
+	attempt := 0
+	for {
+		streamCtx, cancel := context.WithCancel(ctx)
+		var received, timedOut int32
+		watchdog := time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&timedOut, 1)
+			cancel()
+		})
+
+		err := stream(streamCtx, func() {
+			atomic.StoreInt32(&received, 1)
+			watchdog.Reset(timeout)
+		})
+		watchdog.Stop()
+		cancel()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if atomic.LoadInt32(&timedOut) == 1 {
+			err = ErrHeartbeatTimeout
+		} else if err == nil {
+			err = ErrStreamClosed
+		}
+		if !reconnectable(err) {
+			return unwrapMessageError(err)
+		}
+
+		if atomic.LoadInt32(&received) == 1 {
+			attempt = 0
+		}
+		attempt++
+		if config.MaxAttempts > 0 && attempt > config.MaxAttempts {
+			return err
+		}
+
+		delay := backoff.delay(attempt, err)
+		if config.OnReconnect != nil {
+			config.OnReconnect(ReconnectEvent{Attempt: attempt, Err: err, Delay: delay})
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reconnectable reports whether a stream that ended with err may succeed if reconnected
+func reconnectable(err error) bool {
+	if _, ok := err.(*messageError); ok {
+		return false
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// transactionStreamResponse wraps a transaction fetched through the rest api as if it
+// had been received from the transaction stream
+func transactionStreamResponse(t TypedTransaction) TransactionStreamResponse {
+	base := t.Base()
+	return TransactionStreamResponse{
+		Type:          string(base.Type),
+		Time:          base.Time.Format(time.RFC3339Nano),
+		TransactionID: base.ID,
+		AccountID:     base.AccountID,
+		BatchID:       base.BatchID,
+		RequestID:     base.RequestID,
+		Transaction:   AnyTransaction{t},
+	}
+}
+
+// transactionIDAfter reports whether transaction ID a comes after b. IDs are compared
+// as numbers, as the api assigns them in increasing order.
+func transactionIDAfter(a, b string) bool {
+	x, errA := strconv.ParseUint(a, 10, 64)
+	y, errB := strconv.ParseUint(b, 10, 64)
+	if errA != nil || errB != nil {
+		return a != b
+	}
+	return x > y
+}
@@ -0,0 +1,300 @@
+package goanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newSupervisorTestConnection(server *httptest.Server) *StreamingConnection {
+	sc := NewStreamingConnection(&Connection{
+		hostname:   server.URL,
+		accountID:  "test-account",
+		authHeader: "Bearer test-token",
+		client:     *server.Client(),
+	})
+	sc.streamURL = server.URL
+	return sc
+}
+
+func TestSupervisePricesReconnects(t *testing.T) {
+	defer logTestResult(t, "SupervisePricesReconnects")
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&connections, 1)
+		fmt.Fprintf(w, `{"type":"PRICE","instrument":"EUR_USD","time":"%d"}`+"\n", n)
+		w.(http.Flusher).Flush()
+		if n > 1 {
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	sc := newSupervisorTestConnection(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var events []ReconnectEvent
+	var times []string
+	config := SupervisorConfig{
+		BaseDelay:   time.Millisecond,
+		OnReconnect: func(e ReconnectEvent) { events = append(events, e) },
+	}
+	err := sc.SupervisePrices(ctx, []string{"EUR_USD"}, config, func(response PricingStreamResponse) {
+		times = append(times, response.Time)
+		if len(times) == 2 {
+			cancel()
+		}
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !reflect.DeepEqual(times, []string{"1", "2"}) {
+		t.Errorf("Expected a price from each connection, got %v", times)
+	}
+	if len(events) != 1 || events[0].Attempt != 1 || !errors.Is(events[0].Err, ErrStreamClosed) {
+		t.Errorf("Expected one reconnect after the stream closed, got %+v", events)
+	}
+}
+
+func TestSupervisePricesHeartbeatTimeout(t *testing.T) {
+	defer logTestResult(t, "SupervisePricesHeartbeatTimeout")
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if atomic.AddInt32(&connections, 1) > 1 {
+			fmt.Fprintln(w, `{"type":"PRICE","instrument":"EUR_USD"}`)
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	sc := newSupervisorTestConnection(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var events []ReconnectEvent
+	config := SupervisorConfig{
+		HeartbeatTimeout: 50 * time.Millisecond,
+		BaseDelay:        time.Millisecond,
+		OnReconnect:      func(e ReconnectEvent) { events = append(events, e) },
+	}
+	err := sc.SupervisePrices(ctx, []string{"EUR_USD"}, config, func(response PricingStreamResponse) {
+		cancel()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(events) != 1 || !errors.Is(events[0].Err, ErrHeartbeatTimeout) {
+		t.Errorf("Expected one reconnect after the heartbeat timeout, got %+v", events)
+	}
+}
+
+func TestSupervisePricesPermanentError(t *testing.T) {
+	defer logTestResult(t, "SupervisePricesPermanentError")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errorMessage":"Insufficient authorization to perform request."}`))
+	}))
+	defer server.Close()
+
+	sc := newSupervisorTestConnection(server)
+
+	config := SupervisorConfig{
+		BaseDelay:   time.Millisecond,
+		OnReconnect: func(e ReconnectEvent) { t.Errorf("Unexpected reconnect: %+v", e) },
+	}
+	err := sc.SupervisePrices(context.Background(), []string{"EUR_USD"}, config, func(PricingStreamResponse) {})
+
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
+
+func TestSupervisePricesMaxAttempts(t *testing.T) {
+	defer logTestResult(t, "SupervisePricesMaxAttempts")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sc := newSupervisorTestConnection(server)
+
+	reconnects := 0
+	config := SupervisorConfig{
+		BaseDelay:   time.Millisecond,
+		MaxAttempts: 3,
+		OnReconnect: func(ReconnectEvent) { reconnects++ },
+	}
+	err := sc.SupervisePrices(context.Background(), []string{"EUR_USD"}, config, func(PricingStreamResponse) {})
+
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected a 503 APIError, got %v", err)
+	}
+	if reconnects != 3 {
+		t.Errorf("Expected 3 reconnects, got %d", reconnects)
+	}
+}
+
+func TestSuperviseTransactionsBackfill(t *testing.T) {
+	defer logTestResult(t, "SuperviseTransactionsBackfill")
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/test-account/transactions/stream":
+			if atomic.AddInt32(&connections, 1) == 1 {
+				fmt.Fprintln(w, `{"type":"ORDER_FILL","id":"5","accountID":"test-account"}`)
+				return
+			}
+			fmt.Fprintln(w, `{"type":"ORDER_FILL","id":"7","accountID":"test-account"}`)
+			fmt.Fprintln(w, `{"type":"ORDER_FILL","id":"8","accountID":"test-account"}`)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case "/accounts/test-account/transactions/sinceid":
+			if id := r.URL.Query().Get("id"); id != "5" {
+				t.Errorf("Expected backfill since 5, got %s", id)
+			}
+			w.Write([]byte(`{"transactions": [
+				{"type":"MARKET_ORDER","id":"6","accountID":"test-account"},
+				{"type":"ORDER_FILL","id":"7","accountID":"test-account"}
+			], "lastTransactionID": "7"}`))
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	sc := newSupervisorTestConnection(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []string
+	err := sc.SuperviseTransactions(ctx, SupervisorConfig{BaseDelay: time.Millisecond}, func(response TransactionStreamResponse) {
+		ids = append(ids, response.TransactionID)
+		if response.Transaction.Base().ID != response.TransactionID {
+			t.Errorf("Expected transaction %s to be decoded, got %+v", response.TransactionID, response.Transaction)
+		}
+		if response.TransactionID == "8" {
+			cancel()
+		}
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"5", "6", "7", "8"}) {
+		t.Errorf("Expected transactions 5 to 8 once each, got %v", ids)
+	}
+}
+
+func TestSuperviseTransactionsBackfillPages(t *testing.T) {
+	defer logTestResult(t, "SuperviseTransactionsBackfillPages")
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/test-account/transactions/stream":
+			if atomic.AddInt32(&connections, 1) == 1 {
+				fmt.Fprintln(w, `{"type":"ORDER_FILL","id":"5","accountID":"test-account"}`)
+				return
+			}
+			fmt.Fprintln(w, `{"type":"ORDER_FILL","id":"10","accountID":"test-account"}`)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case "/accounts/test-account/transactions/sinceid":
+			// Each page takes over half the heartbeat timeout, so the backfill only
+			// completes if every page counts as a sign of life
+			time.Sleep(30 * time.Millisecond)
+			id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+			fmt.Fprintf(w, `{"transactions": [{"type":"ORDER_FILL","id":"%d"}], "lastTransactionID": "9"}`, id+1)
+		}
+	}))
+	defer server.Close()
+
+	sc := newSupervisorTestConnection(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []string
+	err := sc.SuperviseTransactions(ctx, SupervisorConfig{
+		HeartbeatTimeout: 50 * time.Millisecond,
+		BaseDelay:        time.Millisecond,
+		MaxAttempts:      1,
+	}, func(response TransactionStreamResponse) {
+		ids = append(ids, response.TransactionID)
+		if response.TransactionID == "10" {
+			cancel()
+		}
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"5", "6", "7", "8", "9", "10"}) {
+		t.Errorf("Expected transactions 5 to 10 once each, got %v", ids)
+	}
+}
+
+func TestSuperviseTransactionsBackfillFromHeartbeat(t *testing.T) {
+	defer logTestResult(t, "SuperviseTransactionsBackfillFromHeartbeat")
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/test-account/transactions/stream":
+			if atomic.AddInt32(&connections, 1) == 1 {
+				fmt.Fprintln(w, `{"type":"HEARTBEAT","lastTransactionID":"3"}`)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case "/accounts/test-account/transactions/sinceid":
+			if id := r.URL.Query().Get("id"); id != "3" {
+				t.Errorf("Expected backfill since 3, got %s", id)
+			}
+			w.Write([]byte(`{"transactions": [{"type":"ORDER_FILL","id":"4"}], "lastTransactionID": "4"}`))
+		}
+	}))
+	defer server.Close()
+
+	sc := newSupervisorTestConnection(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []string
+	err := sc.SuperviseTransactions(ctx, SupervisorConfig{BaseDelay: time.Millisecond}, func(response TransactionStreamResponse) {
+		ids = append(ids, response.TransactionID)
+		cancel()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"4"}) {
+		t.Errorf("Expected transaction 4 to be backfilled, got %v", ids)
+	}
+}
@@ -3,6 +3,12 @@ package goanda
 // Supporting OANDA docs - http://developer.oanda.com/rest-live-v20/trade-ep/
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,18 +25,18 @@ type ReceivedTrade struct {
 type Trade struct {
 	ID                    string                 `json:"id"`
 	Instrument            string                 `json:"instrument"`
-	Price                 string                 `json:"price"`
+	Price                 Decimal                `json:"price"`
 	OpenTime              time.Time              `json:"openTime"`
 	State                 string                 `json:"state"`
-	InitialUnits          string                 `json:"initialUnits"`
-	InitialMarginRequired string                 `json:"initialMarginRequired"`
-	CurrentUnits          string                 `json:"currentUnits"`
-	RealizedPL            string                 `json:"realizedPL"`
-	UnrealizedPL          string                 `json:"unrealizedPL"`
-	MarginUsed            string                 `json:"marginUsed"`
-	AverageClosePrice     string                 `json:"averageClosePrice,omitempty"`
+	InitialUnits          Decimal                `json:"initialUnits"`
+	InitialMarginRequired Decimal                `json:"initialMarginRequired"`
+	CurrentUnits          Decimal                `json:"currentUnits"`
+	RealizedPL            Decimal                `json:"realizedPL"`
+	UnrealizedPL          Decimal                `json:"unrealizedPL"`
+	MarginUsed            Decimal                `json:"marginUsed"`
+	AverageClosePrice     Decimal                `json:"averageClosePrice,omitempty"`
 	ClosingTransactionIDs []string               `json:"closingTransactionIDs,omitempty"`
-	Financing             string                 `json:"financing"`
+	Financing             Decimal                `json:"financing"`
 	CloseTime             time.Time              `json:"closeTime,omitempty"`
 	ClientExtensions      *OrderExtensions       `json:"clientExtensions,omitempty"`
 	TakeProfitOrder       *TakeProfitOrder       `json:"takeProfitOrder,omitempty"`
@@ -44,7 +50,7 @@ type TakeProfitOrder struct {
 	Type             string           `json:"type"`
 	TradeID          string           `json:"tradeID"`
 	ClientTradeID    string           `json:"clientTradeID,omitempty"`
-	Price            string           `json:"price"`
+	Price            Decimal          `json:"price"`
 	TimeInForce      string           `json:"timeInForce"`
 	TriggerCondition string           `json:"triggerCondition"`
 	State            string           `json:"state"`
@@ -57,7 +63,7 @@ type StopLossOrder struct {
 	Type             string           `json:"type"`
 	TradeID          string           `json:"tradeID"`
 	ClientTradeID    string           `json:"clientTradeID,omitempty"`
-	Price            string           `json:"price"`
+	Price            Decimal          `json:"price"`
 	TimeInForce      string           `json:"timeInForce"`
 	TriggerCondition string           `json:"triggerCondition"`
 	State            string           `json:"state"`
@@ -71,28 +77,30 @@ type TrailingStopLossOrder struct {
 	Type             string           `json:"type"`
 	TradeID          string           `json:"tradeID"`
 	ClientTradeID    string           `json:"clientTradeID,omitempty"`
-	Distance         string           `json:"distance"`
+	Distance         Decimal          `json:"distance"`
 	TimeInForce      string           `json:"timeInForce"`
 	TriggerCondition string           `json:"triggerCondition"`
 	State            string           `json:"state"`
 	ClientExtensions *OrderExtensions `json:"clientExtensions,omitempty"`
 }
 
+// CloseTradePayload specifies how much of a trade to close, CloseAll or a number of units.
+// Units are left out when unset, which closes the whole trade.
 type CloseTradePayload struct {
-	Units string `json:"units"`
+	Units CloseUnits `json:"units,omitempty"`
 }
 
 type ModifiedTrade struct {
 	OrderCreateTransaction struct {
-		Type         string `json:"type"`
-		Instrument   string `json:"instrument"`
-		Units        string `json:"units"`
-		TimeInForce  string `json:"timeInForce"`
-		PositionFill string `json:"positionFill"`
-		Reason       string `json:"reason"`
+		Type         string  `json:"type"`
+		Instrument   string  `json:"instrument"`
+		Units        Decimal `json:"units"`
+		TimeInForce  string  `json:"timeInForce"`
+		PositionFill string  `json:"positionFill"`
+		Reason       string  `json:"reason"`
 		TradeClose   struct {
-			Units   string `json:"units"`
-			TradeID string `json:"tradeID"`
+			Units   Decimal `json:"units"`
+			TradeID string  `json:"tradeID"`
 		} `json:"tradeClose"`
 		ID        string    `json:"id"`
 		UserID    int       `json:"userID"`
@@ -104,28 +112,28 @@ type ModifiedTrade struct {
 	OrderFillTransaction struct {
 		Type           string    `json:"type"`
 		Instrument     string    `json:"instrument"`
-		Units          string    `json:"units"`
-		Price          string    `json:"price"`
+		Units          Decimal   `json:"units"`
+		Price          Decimal   `json:"price"`
 		FullPrice      FullPrice `json:"fullPrice"`
-		PL             string    `json:"pl"`
-		Financing      string    `json:"financing"`
-		Commission     string    `json:"commission"`
-		AccountBalance string    `json:"accountBalance"`
+		PL             Decimal   `json:"pl"`
+		Financing      Decimal   `json:"financing"`
+		Commission     Decimal   `json:"commission"`
+		AccountBalance Decimal   `json:"accountBalance"`
 		TradeOpened    string    `json:"tradeOpened"`
 		TimeInForce    string    `json:"timeInForce"`
 		PositionFill   string    `json:"positionFill"`
 		Reason         string    `json:"reason"`
 		TradesClosed   []struct {
-			TradeID    string `json:"tradeID"`
-			Units      string `json:"units"`
-			RealizedPL string `json:"realizedPL"`
-			Financing  string `json:"financing"`
+			TradeID    string  `json:"tradeID"`
+			Units      Decimal `json:"units"`
+			RealizedPL Decimal `json:"realizedPL"`
+			Financing  Decimal `json:"financing"`
 		} `json:"tradesClosed"`
 		TradeReduced struct {
-			TradeID    string `json:"tradeID"`
-			Units      string `json:"units"`
-			RealizedPL string `json:"realizedPL"`
-			Financing  string `json:"financing"`
+			TradeID    string  `json:"tradeID"`
+			Units      Decimal `json:"units"`
+			RealizedPL Decimal `json:"realizedPL"`
+			Financing  Decimal `json:"financing"`
 		} `json:"tradeReduced"`
 		ID            string    `json:"id"`
 		UserID        int       `json:"userID"`
@@ -151,61 +159,376 @@ type ModifiedTrade struct {
 	LastTransactionID     string   `json:"lastTransactionID"`
 }
 
+// TakeProfitDetails specifies a take profit order for SetTradeDependentOrders
+type TakeProfitDetails struct {
+	Price            Decimal          `json:"price"`
+	TimeInForce      TimeInForce      `json:"timeInForce,omitempty"`
+	GtdTime          string           `json:"gtdTime,omitempty"`
+	ClientExtensions *OrderExtensions `json:"clientExtensions,omitempty"`
+}
+
+// StopLossDetails specifies a stop loss order for SetTradeDependentOrders, either at
+// a price or a distance from the trade's price
+type StopLossDetails struct {
+	Price            Decimal          `json:"price,omitempty"`
+	Distance         Decimal          `json:"distance,omitempty"`
+	TimeInForce      TimeInForce      `json:"timeInForce,omitempty"`
+	GtdTime          string           `json:"gtdTime,omitempty"`
+	ClientExtensions *OrderExtensions `json:"clientExtensions,omitempty"`
+}
+
+// GuaranteedStopLossDetails specifies a guaranteed stop loss order for
+// SetTradeDependentOrders, either at a price or a distance from the trade's price
+type GuaranteedStopLossDetails struct {
+	Price            Decimal          `json:"price,omitempty"`
+	Distance         Decimal          `json:"distance,omitempty"`
+	TimeInForce      TimeInForce      `json:"timeInForce,omitempty"`
+	GtdTime          string           `json:"gtdTime,omitempty"`
+	ClientExtensions *OrderExtensions `json:"clientExtensions,omitempty"`
+}
+
+// TrailingStopLossDetails specifies a trailing stop loss order for SetTradeDependentOrders
+type TrailingStopLossDetails struct {
+	Distance         Decimal          `json:"distance"`
+	TimeInForce      TimeInForce      `json:"timeInForce,omitempty"`
+	GtdTime          string           `json:"gtdTime,omitempty"`
+	ClientExtensions *OrderExtensions `json:"clientExtensions,omitempty"`
+}
+
+// TradeDependentOrders creates, replaces or cancels the orders closing a trade. Orders
+// left nil are unchanged, and the Cancel flags cancel the trade's existing order.
+type TradeDependentOrders struct {
+	TakeProfit         *TakeProfitDetails
+	StopLoss           *StopLossDetails
+	GuaranteedStopLoss *GuaranteedStopLossDetails
+	TrailingStopLoss   *TrailingStopLossDetails
+
+	CancelTakeProfit         bool
+	CancelStopLoss           bool
+	CancelGuaranteedStopLoss bool
+	CancelTrailingStopLoss   bool
+}
+
+// MarshalJSON sends a cancelled order as null, which is how the api cancels it
+func (d TradeDependentOrders) MarshalJSON() ([]byte, error) {
+	body := map[string]interface{}{}
+
+	set := func(key string, details interface{}, isNil bool, cancel bool) error {
+		if cancel && !isNil {
+			return fmt.Errorf("goanda: %s can't be both set and cancelled", key)
+		}
+		if cancel {
+			body[key] = nil
+		} else if !isNil {
+			body[key] = details
+		}
+		return nil
+	}
+
+	if err := set("takeProfit", d.TakeProfit, d.TakeProfit == nil, d.CancelTakeProfit); err != nil {
+		return nil, err
+	}
+	if err := set("stopLoss", d.StopLoss, d.StopLoss == nil, d.CancelStopLoss); err != nil {
+		return nil, err
+	}
+	if err := set("guaranteedStopLoss", d.GuaranteedStopLoss, d.GuaranteedStopLoss == nil, d.CancelGuaranteedStopLoss); err != nil {
+		return nil, err
+	}
+	if err := set("trailingStopLoss", d.TrailingStopLoss, d.TrailingStopLoss == nil, d.CancelTrailingStopLoss); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(body)
+}
+
+// DependentOrdersResponse holds the transactions created by SetTradeDependentOrders.
+// Only the transactions for the orders that changed are set.
+type DependentOrdersResponse struct {
+	TakeProfitOrderCancelTransaction         *OrderCancelTransaction             `json:"takeProfitOrderCancelTransaction,omitempty"`
+	TakeProfitOrderTransaction               *TakeProfitOrderTransaction         `json:"takeProfitOrderTransaction,omitempty"`
+	TakeProfitOrderFillTransaction           *OrderFillTransaction               `json:"takeProfitOrderFillTransaction,omitempty"`
+	TakeProfitOrderCreatedCancelTransaction  *OrderCancelTransaction             `json:"takeProfitOrderCreatedCancelTransaction,omitempty"`
+	StopLossOrderCancelTransaction           *OrderCancelTransaction             `json:"stopLossOrderCancelTransaction,omitempty"`
+	StopLossOrderTransaction                 *StopLossOrderTransaction           `json:"stopLossOrderTransaction,omitempty"`
+	StopLossOrderFillTransaction             *OrderFillTransaction               `json:"stopLossOrderFillTransaction,omitempty"`
+	StopLossOrderCreatedCancelTransaction    *OrderCancelTransaction             `json:"stopLossOrderCreatedCancelTransaction,omitempty"`
+	TrailingStopLossOrderCancelTransaction   *OrderCancelTransaction             `json:"trailingStopLossOrderCancelTransaction,omitempty"`
+	TrailingStopLossOrderTransaction         *TrailingStopLossOrderTransaction   `json:"trailingStopLossOrderTransaction,omitempty"`
+	GuaranteedStopLossOrderCancelTransaction *OrderCancelTransaction             `json:"guaranteedStopLossOrderCancelTransaction,omitempty"`
+	GuaranteedStopLossOrderTransaction       *GuaranteedStopLossOrderTransaction `json:"guaranteedStopLossOrderTransaction,omitempty"`
+	RelatedTransactionIDs                    []string                            `json:"relatedTransactionIDs"`
+	LastTransactionID                        string                              `json:"lastTransactionID"`
+}
+
 type FullPrice struct {
-	CloseoutBid string       `json:"closeoutBid"`
-	CloseoutAsk string       `json:"closeoutAsk"`
+	CloseoutBid Decimal      `json:"closeoutBid"`
+	CloseoutAsk Decimal      `json:"closeoutAsk"`
 	Timestamp   string       `json:"timestamp"`
 	Bids        []PriceLevel `json:"bids"`
 	Asks        []PriceLevel `json:"asks"`
 }
 
 type PriceLevel struct {
-	Price     string `json:"price"`
-	Liquidity string `json:"liquidity"`
+	Price     Decimal `json:"price"`
+	Liquidity string  `json:"liquidity"`
 }
 
 func (c *Connection) GetTradesForInstrument(instrument string) (ReceivedTrades, error) {
+	return c.GetTradesForInstrumentContext(context.Background(), instrument)
+}
+
+// GetTradesForInstrumentContext is GetTradesForInstrument bound to the given context
+func (c *Connection) GetTradesForInstrumentContext(ctx context.Context, instrument string) (ReceivedTrades, error) {
+	return c.ListTradesContext(ctx, TradeListOptions{Instrument: instrument})
+}
+
+// TradeState is the state of a trade, used to filter the trades listed
+type TradeState string
+
+const (
+	TradeStateOpen               TradeState = "OPEN"
+	TradeStateClosed             TradeState = "CLOSED"
+	TradeStateCloseWhenTradeable TradeState = "CLOSE_WHEN_TRADEABLE"
+	TradeStateAll                TradeState = "ALL"
+)
+
+// TradeListOptions filters the trades returned by ListTrades. Zero values are left
+// to the api's defaults, which list up to 50 open trades.
+type TradeListOptions struct {
+	IDs        []string
+	State      TradeState
+	Instrument string
+	// Count is the maximum number of trades to return, at most 500
+	Count int
+	// BeforeID only returns trades with an ID less than the given one
+	BeforeID string
+	// OpenedAfter is only used by IterateTrades, which stops at the first trade opened
+	// before it. The api has no time filter, so it isn't sent.
+	OpenedAfter time.Time
+}
+
+func (o TradeListOptions) query() string {
+	q := url.Values{}
+	if len(o.IDs) > 0 {
+		q.Set("ids", strings.Join(o.IDs, ","))
+	}
+	if o.State != "" {
+		q.Set("state", string(o.State))
+	}
+	if o.Instrument != "" {
+		q.Set("instrument", o.Instrument)
+	}
+	if o.Count > 0 {
+		q.Set("count", strconv.Itoa(o.Count))
+	}
+	if o.BeforeID != "" {
+		q.Set("beforeID", o.BeforeID)
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// ListTrades returns the account's trades matching the options, most recent first
+func (c *Connection) ListTrades(opts TradeListOptions) (ReceivedTrades, error) {
+	return c.ListTradesContext(context.Background(), opts)
+}
+
+// ListTradesContext is ListTrades bound to the given context
+func (c *Connection) ListTradesContext(ctx context.Context, opts TradeListOptions) (ReceivedTrades, error) {
 	rt := ReceivedTrades{}
-	err := c.getAndUnmarshal(
-		"/accounts/"+
-			c.accountID+
-			"/trades"+
-			"?instrument="+
-			instrument,
-		&rt,
-	)
+	err := c.getAndUnmarshal(ctx, "/accounts/"+c.accountID+"/trades"+opts.query(), &rt)
 	return rt, err
 }
 
+// TradeIterator lazily pages backwards through the account's trades, see IterateTrades
+type TradeIterator struct {
+	c     *Connection
+	ctx   context.Context
+	opts  TradeListOptions
+	page  []Trade
+	trade Trade
+	done  bool
+	err   error
+}
+
+// IterateTrades returns an iterator over the trades matching the options, most recent
+// first. Pages are only requested as the iteration reaches them, each with a BeforeID
+// of the oldest trade seen, until a page comes back short, a trade opened before
+// OpenedAfter is reached or the caller stops calling Next. The api lists trades of every
+// state by descending ID, and IDs increase with the time trades are opened, so no trade
+// after the first one opened before OpenedAfter can be newer.
+//
+//	it := c.IterateTrades(ctx, goanda.TradeListOptions{
+//		State:       goanda.TradeStateClosed,
+//		Instrument:  "EUR_USD",
+//		OpenedAfter: time.Now().AddDate(0, 0, -1),
+//	})
+//	for it.Next() {
+//		pl = pl.Add(it.Trade().RealizedPL)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func (c *Connection) IterateTrades(ctx context.Context, opts TradeListOptions) *TradeIterator {
+	if opts.Count <= 0 {
+		opts.Count = 50
+	}
+	return &TradeIterator{c: c, ctx: ctx, opts: opts}
+}
+
+// Next advances to the next trade, fetching the next page when needed. It returns false
+// when there are no more trades or a request failed, see Err.
+func (it *TradeIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+
+		rt, err := it.c.ListTradesContext(it.ctx, it.opts)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = rt.Trades
+		if len(it.page) < it.opts.Count {
+			it.done = true
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+		it.opts.BeforeID = it.page[len(it.page)-1].ID
+	}
+
+	it.trade, it.page = it.page[0], it.page[1:]
+	// Trades come newest first, so the rest were all opened before OpenedAfter too
+	if !it.opts.OpenedAfter.IsZero() && it.trade.OpenTime.Before(it.opts.OpenedAfter) {
+		it.page, it.done = nil, true
+		return false
+	}
+	return true
+}
+
+// Trade returns the current trade
+func (it *TradeIterator) Trade() Trade {
+	return it.trade
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *TradeIterator) Err() error {
+	return it.err
+}
+
 func (c *Connection) GetOpenTrades() (ReceivedTrades, error) {
+	return c.GetOpenTradesContext(context.Background())
+}
+
+// GetOpenTradesContext is GetOpenTrades bound to the given context
+func (c *Connection) GetOpenTradesContext(ctx context.Context) (ReceivedTrades, error) {
 	rt := ReceivedTrades{}
-	err := c.getAndUnmarshal("/accounts/"+c.accountID+"/openTrades", &rt)
+	err := c.getAndUnmarshal(ctx, "/accounts/"+c.accountID+"/openTrades", &rt)
 	return rt, err
 }
 
+// GetTrade returns the trade with the given ID, or the given client ID using ClientIDSpecifier
 func (c *Connection) GetTrade(ticket string) (ReceivedTrade, error) {
+	return c.GetTradeContext(context.Background(), ticket)
+}
+
+// GetTradeContext is GetTrade bound to the given context
+func (c *Connection) GetTradeContext(ctx context.Context, ticket string) (ReceivedTrade, error) {
 	rt := ReceivedTrade{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/trades/"+
-			ticket,
+			url.PathEscape(ticket),
 		&rt,
 	)
 	return rt, err
 }
 
-// Default is close the whole position using the string "ALL" in body.units
+// ReduceTradeSize closes part of the trade with the given ID, or the given client ID
+// using ClientIDSpecifier. Default is close the whole position using the string "ALL" in body.units
 func (c *Connection) ReduceTradeSize(ticket string, body CloseTradePayload) (ModifiedTrade, error) {
+	return c.ReduceTradeSizeContext(context.Background(), ticket, body)
+}
+
+// ReduceTradeSizeContext is ReduceTradeSize bound to the given context
+func (c *Connection) ReduceTradeSizeContext(ctx context.Context, ticket string, body CloseTradePayload) (ModifiedTrade, error) {
 	mt := ModifiedTrade{}
 	err := c.putAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/trades/"+
-			ticket+
+			url.PathEscape(ticket)+
 			"/close",
 		body,
 		&mt,
+		// Closing part of a trade twice would close too much
+		body.Units == CloseAll || body.Units == "",
 	)
 	return mt, err
 }
+
+// SetTradeDependentOrders creates, replaces or cancels the take profit, stop loss,
+// guaranteed stop loss and trailing stop loss orders of an open trade
+func (c *Connection) SetTradeDependentOrders(tradeSpecifier string, orders TradeDependentOrders) (DependentOrdersResponse, error) {
+	return c.SetTradeDependentOrdersContext(context.Background(), tradeSpecifier, orders)
+}
+
+// SetTradeDependentOrdersContext is SetTradeDependentOrders bound to the given context
+func (c *Connection) SetTradeDependentOrdersContext(ctx context.Context, tradeSpecifier string, orders TradeDependentOrders) (DependentOrdersResponse, error) {
+	dr := DependentOrdersResponse{}
+	err := c.putAndUnmarshal(
+		ctx,
+		"/accounts/"+
+			c.accountID+
+			"/trades/"+
+			url.PathEscape(tradeSpecifier)+
+			"/orders",
+		orders,
+		&dr,
+		true,
+	)
+	return dr, err
+}
+
+// TradeClientExtensionsResponse holds the transaction created by UpdateTradeClientExtensions
+type TradeClientExtensionsResponse struct {
+	TradeClientExtensionsModifyTransaction TradeClientExtensionsModifyTransaction `json:"tradeClientExtensionsModifyTransaction"`
+	RelatedTransactionIDs                  []string                               `json:"relatedTransactionIDs"`
+	LastTransactionID                      string                                 `json:"lastTransactionID"`
+}
+
+// UpdateTradeClientExtensions replaces the client extensions of an open trade
+func (c *Connection) UpdateTradeClientExtensions(tradeSpecifier string, clientExtensions OrderExtensions) (TradeClientExtensionsResponse, error) {
+	return c.UpdateTradeClientExtensionsContext(context.Background(), tradeSpecifier, clientExtensions)
+}
+
+// UpdateTradeClientExtensionsContext is UpdateTradeClientExtensions bound to the given context
+func (c *Connection) UpdateTradeClientExtensionsContext(ctx context.Context, tradeSpecifier string, clientExtensions OrderExtensions) (TradeClientExtensionsResponse, error) {
+	body := struct {
+		ClientExtensions OrderExtensions `json:"clientExtensions"`
+	}{clientExtensions}
+
+	tr := TradeClientExtensionsResponse{}
+	err := c.putAndUnmarshal(
+		ctx,
+		"/accounts/"+
+			c.accountID+
+			"/trades/"+
+			url.PathEscape(tradeSpecifier)+
+			"/clientExtensions",
+		body,
+		&tr,
+		true,
+	)
+	return tr, err
+}
@@ -1,9 +1,11 @@
 package goanda
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -118,6 +120,106 @@ func TestGetTradesForInstrument(t *testing.T) {
 	}
 }
 
+func TestListTrades(t *testing.T) {
+	defer logTestResult(t, "TestListTrades")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/test-account/trades" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+
+		want := "beforeID=9&count=100&ids=7%2C8&instrument=EUR_USD&state=CLOSED"
+		if r.URL.RawQuery != want {
+			t.Errorf("Expected query %s, got %s", want, r.URL.RawQuery)
+		}
+
+		json.NewEncoder(w).Encode(ReceivedTrades{LastTransactionID: "1234"})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	_, err := c.ListTrades(TradeListOptions{
+		IDs:        []string{"7", "8"},
+		State:      TradeStateClosed,
+		Instrument: "EUR_USD",
+		Count:      100,
+		BeforeID:   "9",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestIterateTrades(t *testing.T) {
+	defer logTestResult(t, "TestIterateTrades")
+
+	now := time.Now()
+	trades := []Trade{
+		{ID: "6", OpenTime: now.Add(-time.Hour)},
+		{ID: "5", OpenTime: now.Add(-2 * time.Hour)},
+		{ID: "4", OpenTime: now.Add(-3 * time.Hour)},
+		{ID: "3", OpenTime: now.Add(-48 * time.Hour)},
+		{ID: "2", OpenTime: now.Add(-49 * time.Hour)},
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("state") != "CLOSED" {
+			t.Errorf("Unexpected state: %s", r.URL.Query().Get("state"))
+		}
+
+		page := trades
+		if beforeID := r.URL.Query().Get("beforeID"); beforeID != "" {
+			for i, trade := range trades {
+				if trade.ID == beforeID {
+					page = trades[i+1:]
+				}
+			}
+		}
+		if len(page) > 2 {
+			page = page[:2]
+		}
+		json.NewEncoder(w).Encode(ReceivedTrades{Trades: page})
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	// Closed trades are listed by descending ID too, so the walk stops at trade 3
+	// without fetching the last page
+	it := c.IterateTrades(context.Background(), TradeListOptions{
+		State:       TradeStateClosed,
+		Count:       2,
+		OpenedAfter: now.Add(-24 * time.Hour),
+	})
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Trade().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(ids, ",") != "6,5,4" {
+		t.Errorf("Expected trades 6,5,4, got %v", ids)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
 func TestGetOpenTrades(t *testing.T) {
 	defer logTestResult(t, "TestGetOpenTrades")
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -293,15 +395,15 @@ func TestReduceTradeSize(t *testing.T) {
 
 		response := ModifiedTrade{
 			OrderCreateTransaction: struct {
-				Type         string `json:"type"`
-				Instrument   string `json:"instrument"`
-				Units        string `json:"units"`
-				TimeInForce  string `json:"timeInForce"`
-				PositionFill string `json:"positionFill"`
-				Reason       string `json:"reason"`
+				Type         string  `json:"type"`
+				Instrument   string  `json:"instrument"`
+				Units        Decimal `json:"units"`
+				TimeInForce  string  `json:"timeInForce"`
+				PositionFill string  `json:"positionFill"`
+				Reason       string  `json:"reason"`
 				TradeClose   struct {
-					Units   string `json:"units"`
-					TradeID string `json:"tradeID"`
+					Units   Decimal `json:"units"`
+					TradeID string  `json:"tradeID"`
 				} `json:"tradeClose"`
 				ID        string    `json:"id"`
 				UserID    int       `json:"userID"`
@@ -314,8 +416,8 @@ func TestReduceTradeSize(t *testing.T) {
 				Instrument: "EUR_USD",
 				Units:      "-50",
 				TradeClose: struct {
-					Units   string `json:"units"`
-					TradeID string `json:"tradeID"`
+					Units   Decimal `json:"units"`
+					TradeID string  `json:"tradeID"`
 				}{
 					Units:   "50",
 					TradeID: "1",
@@ -326,28 +428,28 @@ func TestReduceTradeSize(t *testing.T) {
 			OrderFillTransaction: struct {
 				Type           string    `json:"type"`
 				Instrument     string    `json:"instrument"`
-				Units          string    `json:"units"`
-				Price          string    `json:"price"`
+				Units          Decimal   `json:"units"`
+				Price          Decimal   `json:"price"`
 				FullPrice      FullPrice `json:"fullPrice"`
-				PL             string    `json:"pl"`
-				Financing      string    `json:"financing"`
-				Commission     string    `json:"commission"`
-				AccountBalance string    `json:"accountBalance"`
+				PL             Decimal   `json:"pl"`
+				Financing      Decimal   `json:"financing"`
+				Commission     Decimal   `json:"commission"`
+				AccountBalance Decimal   `json:"accountBalance"`
 				TradeOpened    string    `json:"tradeOpened"`
 				TimeInForce    string    `json:"timeInForce"`
 				PositionFill   string    `json:"positionFill"`
 				Reason         string    `json:"reason"`
 				TradesClosed   []struct {
-					TradeID    string `json:"tradeID"`
-					Units      string `json:"units"`
-					RealizedPL string `json:"realizedPL"`
-					Financing  string `json:"financing"`
+					TradeID    string  `json:"tradeID"`
+					Units      Decimal `json:"units"`
+					RealizedPL Decimal `json:"realizedPL"`
+					Financing  Decimal `json:"financing"`
 				} `json:"tradesClosed"`
 				TradeReduced struct {
-					TradeID    string `json:"tradeID"`
-					Units      string `json:"units"`
-					RealizedPL string `json:"realizedPL"`
-					Financing  string `json:"financing"`
+					TradeID    string  `json:"tradeID"`
+					Units      Decimal `json:"units"`
+					RealizedPL Decimal `json:"realizedPL"`
+					Financing  Decimal `json:"financing"`
 				} `json:"tradeReduced"`
 				ID            string    `json:"id"`
 				UserID        int       `json:"userID"`
@@ -363,10 +465,10 @@ func TestReduceTradeSize(t *testing.T) {
 				Units:      "-50",
 				Price:      "1.1000",
 				TradeReduced: struct {
-					TradeID    string `json:"tradeID"`
-					Units      string `json:"units"`
-					RealizedPL string `json:"realizedPL"`
-					Financing  string `json:"financing"`
+					TradeID    string  `json:"tradeID"`
+					Units      Decimal `json:"units"`
+					RealizedPL Decimal `json:"realizedPL"`
+					Financing  Decimal `json:"financing"`
 				}{
 					TradeID:    "1",
 					Units:      "50",
@@ -405,3 +507,127 @@ func TestReduceTradeSize(t *testing.T) {
 		t.Errorf("Expected OrderFillTransaction.TradeReduced.Units to be 50, got %s", modifiedTrade.OrderFillTransaction.TradeReduced.Units)
 	}
 }
+
+func TestSetTradeDependentOrders(t *testing.T) {
+	defer logTestResult(t, "TestSetTradeDependentOrders")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/test-account/trades/42/orders" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+		if r.Method != "PUT" {
+			t.Errorf("Expected PUT request, got %s", r.Method)
+		}
+
+		var payload map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if string(payload["stopLoss"]) != `{"price":"1.10000","timeInForce":"GTC"}` {
+			t.Errorf("Unexpected stopLoss: %s", payload["stopLoss"])
+		}
+		if string(payload["trailingStopLoss"]) != "null" {
+			t.Errorf("Expected trailingStopLoss to be cancelled, got %s", payload["trailingStopLoss"])
+		}
+		if _, ok := payload["takeProfit"]; ok {
+			t.Error("Expected takeProfit to be left unchanged")
+		}
+
+		w.Write([]byte(`{
+			"stopLossOrderCancelTransaction": {"id": "101", "type": "ORDER_CANCEL", "orderID": "50", "reason": "CLIENT_REQUEST_REPLACED", "replacedByOrderID": "102"},
+			"stopLossOrderTransaction": {"id": "102", "type": "STOP_LOSS_ORDER", "tradeID": "42", "price": "1.10000", "timeInForce": "GTC", "reason": "REPLACEMENT", "replacesOrderID": "50"},
+			"trailingStopLossOrderCancelTransaction": {"id": "103", "type": "ORDER_CANCEL", "orderID": "51", "reason": "CLIENT_REQUEST"},
+			"relatedTransactionIDs": ["101", "102", "103"],
+			"lastTransactionID": "103"
+		}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	response, err := c.SetTradeDependentOrders("42", TradeDependentOrders{
+		StopLoss:               &StopLossDetails{Price: "1.10000", TimeInForce: TimeInForceGTC},
+		CancelTrailingStopLoss: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if response.StopLossOrderTransaction == nil || response.StopLossOrderTransaction.Price != "1.10000" {
+		t.Errorf("Unexpected stop loss order transaction: %+v", response.StopLossOrderTransaction)
+	}
+	if response.StopLossOrderTransaction.ReplacesOrderID != "50" {
+		t.Errorf("Expected the stop loss to replace order 50, got %s", response.StopLossOrderTransaction.ReplacesOrderID)
+	}
+	if response.TrailingStopLossOrderCancelTransaction == nil || response.TrailingStopLossOrderCancelTransaction.OrderID != "51" {
+		t.Errorf("Unexpected trailing stop loss cancel transaction: %+v", response.TrailingStopLossOrderCancelTransaction)
+	}
+	if response.TakeProfitOrderTransaction != nil {
+		t.Error("Expected no take profit order transaction")
+	}
+
+	_, err = c.SetTradeDependentOrders("42", TradeDependentOrders{
+		TakeProfit:       &TakeProfitDetails{Price: "1.2"},
+		CancelTakeProfit: true,
+	})
+	if err == nil {
+		t.Error("Expected an error setting and cancelling the take profit")
+	}
+}
+
+func TestUpdateTradeClientExtensions(t *testing.T) {
+	defer logTestResult(t, "TestUpdateTradeClientExtensions")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/test-account/trades/@my-trade/clientExtensions" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+
+		var payload struct {
+			ClientExtensions OrderExtensions `json:"clientExtensions"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if payload.ClientExtensions.Tag != "strategy-2" {
+			t.Errorf("Expected tag strategy-2, got %s", payload.ClientExtensions.Tag)
+		}
+
+		w.Write([]byte(`{
+			"tradeClientExtensionsModifyTransaction": {
+				"id": "201",
+				"type": "TRADE_CLIENT_EXTENSIONS_MODIFY",
+				"tradeID": "42",
+				"clientTradeID": "my-trade",
+				"tradeClientExtensionsModify": {"id": "my-trade", "tag": "strategy-2"}
+			},
+			"lastTransactionID": "201"
+		}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	response, err := c.UpdateTradeClientExtensions(ClientIDSpecifier("my-trade"), OrderExtensions{ID: "my-trade", Tag: "strategy-2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	transaction := response.TradeClientExtensionsModifyTransaction
+	if transaction.TradeID != "42" {
+		t.Errorf("Expected TradeID to be 42, got %s", transaction.TradeID)
+	}
+	if transaction.TradeClientExtensionsModify == nil || transaction.TradeClientExtensionsModify.Tag != "strategy-2" {
+		t.Errorf("Unexpected client extensions: %+v", transaction.TradeClientExtensionsModify)
+	}
+}
@@ -1,7 +1,12 @@
 package goanda
 
 import (
+	"context"
+	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,73 +21,283 @@ type TransactionPages struct {
 	To                time.Time `json:"to"`
 }
 
+// Transaction is a single transaction, decoded into the concrete type given by its
+// type field, see TypedTransaction
 type Transaction struct {
-	LastTransactionID string `json:"lastTransactionID"`
-	Transaction       struct {
-		AccountBalance string    `json:"accountBalance"`
-		AccountID      string    `json:"accountID"`
-		BatchID        string    `json:"batchID"`
-		Financing      string    `json:"financing"`
-		ID             string    `json:"id"`
-		Instrument     string    `json:"instrument"`
-		OrderID        string    `json:"orderID"`
-		Pl             string    `json:"pl"`
-		Price          string    `json:"price"`
-		Reason         string    `json:"reason"`
-		Time           time.Time `json:"time"`
-		TradeOpened    struct {
-			TradeID string `json:"tradeID"`
-			Units   string `json:"units"`
-		} `json:"tradeOpened"`
-		Type   string `json:"type"`
-		Units  string `json:"units"`
-		UserID int    `json:"userID"`
-	} `json:"transaction"`
+	LastTransactionID string         `json:"lastTransactionID"`
+	Transaction       AnyTransaction `json:"transaction"`
 }
 
 type Transactions struct {
-	LastTransactionID string `json:"lastTransactionID"`
-	Transactions      []struct {
-		AccountBalance string    `json:"accountBalance"`
-		AccountID      string    `json:"accountID"`
-		BatchID        string    `json:"batchID"`
-		Financing      string    `json:"financing"`
-		ID             string    `json:"id"`
-		Instrument     string    `json:"instrument"`
-		OrderID        string    `json:"orderID"`
-		Pl             string    `json:"pl"`
-		Price          string    `json:"price"`
-		Reason         string    `json:"reason"`
-		Time           time.Time `json:"time"`
-		TradeOpened    struct {
-			TradeID string `json:"tradeID"`
-			Units   string `json:"units"`
-		} `json:"tradeOpened"`
-		Type   string `json:"type"`
-		Units  string `json:"units"`
-		UserID int    `json:"userID"`
-	} `json:"transactions"`
+	LastTransactionID string           `json:"lastTransactionID"`
+	Transactions      []AnyTransaction `json:"transactions"`
 }
 
+// GetTransactions returns the pages of transactions between from and to, only those of
+// the given types if any filters are given
+//
 // https://golang.org/pkg/time/#Time.AddDate
 // https://play.golang.org/p/Dw7D4JJ7EC
-func (c *Connection) GetTransactions(from time.Time, to time.Time) (TransactionPages, error) {
+func (c *Connection) GetTransactions(from time.Time, to time.Time, filter ...TransactionFilter) (TransactionPages, error) {
+	return c.GetTransactionsContext(context.Background(), from, to, filter...)
+}
+
+// GetTransactionsContext is GetTransactions bound to the given context
+func (c *Connection) GetTransactionsContext(ctx context.Context, from time.Time, to time.Time, filter ...TransactionFilter) (TransactionPages, error) {
+	return c.GetTransactionPagesContext(ctx, TransactionQuery{From: from, To: to, Type: filter})
+}
+
+// TransactionFilter selects the transactions returned by the transaction queries. Besides
+// the groups below, every transaction type can be used as a filter.
+type TransactionFilter string
+
+const (
+	TransactionFilterOrder                             TransactionFilter = "ORDER"
+	TransactionFilterFunding                           TransactionFilter = "FUNDING"
+	TransactionFilterAdmin                             TransactionFilter = "ADMIN"
+	TransactionFilterCreate                            TransactionFilter = "CREATE"
+	TransactionFilterClose                             TransactionFilter = "CLOSE"
+	TransactionFilterReopen                            TransactionFilter = "REOPEN"
+	TransactionFilterClientConfigure                   TransactionFilter = "CLIENT_CONFIGURE"
+	TransactionFilterClientConfigureReject             TransactionFilter = "CLIENT_CONFIGURE_REJECT"
+	TransactionFilterTransferFunds                     TransactionFilter = "TRANSFER_FUNDS"
+	TransactionFilterTransferFundsReject               TransactionFilter = "TRANSFER_FUNDS_REJECT"
+	TransactionFilterMarketOrder                       TransactionFilter = "MARKET_ORDER"
+	TransactionFilterMarketOrderReject                 TransactionFilter = "MARKET_ORDER_REJECT"
+	TransactionFilterLimitOrder                        TransactionFilter = "LIMIT_ORDER"
+	TransactionFilterLimitOrderReject                  TransactionFilter = "LIMIT_ORDER_REJECT"
+	TransactionFilterStopOrder                         TransactionFilter = "STOP_ORDER"
+	TransactionFilterStopOrderReject                   TransactionFilter = "STOP_ORDER_REJECT"
+	TransactionFilterMarketIfTouchedOrder              TransactionFilter = "MARKET_IF_TOUCHED_ORDER"
+	TransactionFilterMarketIfTouchedOrderReject        TransactionFilter = "MARKET_IF_TOUCHED_ORDER_REJECT"
+	TransactionFilterTakeProfitOrder                   TransactionFilter = "TAKE_PROFIT_ORDER"
+	TransactionFilterTakeProfitOrderReject             TransactionFilter = "TAKE_PROFIT_ORDER_REJECT"
+	TransactionFilterStopLossOrder                     TransactionFilter = "STOP_LOSS_ORDER"
+	TransactionFilterStopLossOrderReject               TransactionFilter = "STOP_LOSS_ORDER_REJECT"
+	TransactionFilterGuaranteedStopLossOrder           TransactionFilter = "GUARANTEED_STOP_LOSS_ORDER"
+	TransactionFilterGuaranteedStopLossOrderReject     TransactionFilter = "GUARANTEED_STOP_LOSS_ORDER_REJECT"
+	TransactionFilterTrailingStopLossOrder             TransactionFilter = "TRAILING_STOP_LOSS_ORDER"
+	TransactionFilterTrailingStopLossOrderReject       TransactionFilter = "TRAILING_STOP_LOSS_ORDER_REJECT"
+	TransactionFilterOrderFill                         TransactionFilter = "ORDER_FILL"
+	TransactionFilterOrderCancel                       TransactionFilter = "ORDER_CANCEL"
+	TransactionFilterOrderCancelReject                 TransactionFilter = "ORDER_CANCEL_REJECT"
+	TransactionFilterOrderClientExtensionsModify       TransactionFilter = "ORDER_CLIENT_EXTENSIONS_MODIFY"
+	TransactionFilterOrderClientExtensionsModifyReject TransactionFilter = "ORDER_CLIENT_EXTENSIONS_MODIFY_REJECT"
+	TransactionFilterTradeClientExtensionsModify       TransactionFilter = "TRADE_CLIENT_EXTENSIONS_MODIFY"
+	TransactionFilterTradeClientExtensionsModifyReject TransactionFilter = "TRADE_CLIENT_EXTENSIONS_MODIFY_REJECT"
+	TransactionFilterMarginCallEnter                   TransactionFilter = "MARGIN_CALL_ENTER"
+	TransactionFilterMarginCallExtend                  TransactionFilter = "MARGIN_CALL_EXTEND"
+	TransactionFilterMarginCallExit                    TransactionFilter = "MARGIN_CALL_EXIT"
+	TransactionFilterDelayedTradeClosure               TransactionFilter = "DELAYED_TRADE_CLOSURE"
+	TransactionFilterDailyFinancing                    TransactionFilter = "DAILY_FINANCING"
+	TransactionFilterDividendAdjustment                TransactionFilter = "DIVIDEND_ADJUSTMENT"
+	TransactionFilterResetResettablePL                 TransactionFilter = "RESET_RESETTABLE_PL"
+)
+
+func joinFilters(filters []TransactionFilter) string {
+	types := make([]string, len(filters))
+	for i, f := range filters {
+		types[i] = string(f)
+	}
+	return strings.Join(types, ",")
+}
+
+// TransactionQuery selects the transactions walked by WalkTransactions
+type TransactionQuery struct {
+	// From and To select the transactions in a time range. To defaults to now.
+	From time.Time
+	To   time.Time
+	// SinceID selects the transactions after the one with the given ID instead of a
+	// time range
+	SinceID string
+	// PageSize is the number of transactions in each page of a time range, at most 1000
+	PageSize int
+	Type     []TransactionFilter
+	// Concurrency is the number of pages requested at once, 4 by default. Pages are
+	// still handed over in ID order.
+	Concurrency int
+}
+
+func (q TransactionQuery) query() string {
+	v := url.Values{}
+	if !q.From.IsZero() {
+		v.Set("from", q.From.Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		v.Set("to", q.To.Format(time.RFC3339))
+	}
+	if q.PageSize > 0 {
+		v.Set("pageSize", strconv.Itoa(q.PageSize))
+	}
+	if len(q.Type) > 0 {
+		v.Set("type", joinFilters(q.Type))
+	}
+	if len(v) == 0 {
+		return ""
+	}
+	return "?" + v.Encode()
+}
+
+// GetTransactionPages returns the URLs of the pages of transactions in the query's time range
+func (c *Connection) GetTransactionPages(q TransactionQuery) (TransactionPages, error) {
+	return c.GetTransactionPagesContext(context.Background(), q)
+}
+
+// GetTransactionPagesContext is GetTransactionPages bound to the given context
+func (c *Connection) GetTransactionPagesContext(ctx context.Context, q TransactionQuery) (TransactionPages, error) {
 	tp := TransactionPages{}
-	err := c.getAndUnmarshal(
-		"/accounts/"+
-			c.accountID+
-			"/transactions?to="+
-			url.QueryEscape(to.Format(time.RFC3339))+
-			"&from="+
-			url.QueryEscape(from.Format(time.RFC3339)),
-		&tp,
-	)
+	err := c.getAndUnmarshal(ctx, "/accounts/"+c.accountID+"/transactions"+q.query(), &tp)
 	return tp, err
 }
 
+// GetTransactionIDRange returns the transactions with IDs from fromID to toID inclusive,
+// only those of the given types if any filters are given
+func (c *Connection) GetTransactionIDRange(fromID string, toID string, filter ...TransactionFilter) (Transactions, error) {
+	return c.GetTransactionIDRangeContext(context.Background(), fromID, toID, filter...)
+}
+
+// GetTransactionIDRangeContext is GetTransactionIDRange bound to the given context
+func (c *Connection) GetTransactionIDRangeContext(ctx context.Context, fromID string, toID string, filter ...TransactionFilter) (Transactions, error) {
+	return c.getTransactionIDRange(ctx, fromID, toID, joinFilters(filter))
+}
+
+func (c *Connection) getTransactionIDRange(ctx context.Context, fromID string, toID string, types string) (Transactions, error) {
+	v := url.Values{}
+	v.Set("from", fromID)
+	v.Set("to", toID)
+	if types != "" {
+		v.Set("type", types)
+	}
+
+	tr := Transactions{}
+	err := c.getAndUnmarshal(ctx, "/accounts/"+c.accountID+"/transactions/idrange?"+v.Encode(), &tr)
+	return tr, err
+}
+
+// WalkTransactions calls fn with every transaction selected by the query, in ID order.
+// For a time range, the pages listed by GetTransactionPages are requested through the
+// idrange endpoint of this connection, a few at a time. For SinceID, the sinceid endpoint
+// is requested until the last transaction is reached. The walk stops at the first error,
+// including one returned by fn.
+func (c *Connection) WalkTransactions(ctx context.Context, q TransactionQuery, fn func(TypedTransaction) error) error {
+	if q.SinceID != "" {
+		return c.walkTransactionsSince(ctx, q, fn)
+	}
+
+	tp, err := c.GetTransactionPagesContext(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	type page struct {
+		transactions []AnyTransaction
+		err          error
+		done         chan struct{}
+	}
+
+	ranges := make([]url.Values, len(tp.Pages))
+	for i, p := range tp.Pages {
+		u, err := url.Parse(p)
+		if err != nil {
+			return fmt.Errorf("goanda: parsing transaction page %q: %w", p, err)
+		}
+		ranges[i] = u.Query()
+	}
+
+	concurrency := q.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// A slot is taken for each page being requested or waiting to be handed to fn, so at
+	// most concurrency pages are held at once
+	slots := make(chan struct{}, concurrency)
+	pages := make([]*page, len(ranges))
+	for i := range pages {
+		pages[i] = &page{done: make(chan struct{})}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, r := range ranges {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			wg.Add(1)
+			go func(p *page, r url.Values) {
+				defer wg.Done()
+				defer close(p.done)
+				tr, err := c.getTransactionIDRange(ctx, r.Get("from"), r.Get("to"), r.Get("type"))
+				p.transactions, p.err = tr.Transactions, err
+			}(pages[i], r)
+		}
+	}()
+
+	for _, p := range pages {
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if p.err != nil {
+			return p.err
+		}
+
+		for _, t := range p.transactions {
+			if err := fn(t.TypedTransaction); err != nil {
+				return err
+			}
+		}
+		p.transactions = nil
+		<-slots
+	}
+	return nil
+}
+
+func (c *Connection) walkTransactionsSince(ctx context.Context, q TransactionQuery, fn func(TypedTransaction) error) error {
+	since := q.SinceID
+	for {
+		tr, err := c.getTransactionsSinceID(ctx, since, joinFilters(q.Type))
+		if err != nil {
+			return err
+		}
+		if len(tr.Transactions) == 0 {
+			return nil
+		}
+
+		for _, t := range tr.Transactions {
+			if err := fn(t.TypedTransaction); err != nil {
+				return err
+			}
+		}
+
+		since = tr.Transactions[len(tr.Transactions)-1].Base().ID
+		if since == tr.LastTransactionID {
+			return nil
+		}
+	}
+}
+
 func (c *Connection) GetTransaction(ticket string) (Transaction, error) {
+	return c.GetTransactionContext(context.Background(), ticket)
+}
+
+// GetTransactionContext is GetTransaction bound to the given context
+func (c *Connection) GetTransactionContext(ctx context.Context, ticket string) (Transaction, error) {
 	tr := Transaction{}
 	err := c.getAndUnmarshal(
+		ctx,
 		"/accounts/"+
 			c.accountID+
 			"/transactions/"+
@@ -92,14 +307,25 @@ func (c *Connection) GetTransaction(ticket string) (Transaction, error) {
 	return tr, err
 }
 
-func (c *Connection) GetTransactionsSinceId(id string) (Transactions, error) {
+// GetTransactionsSinceId returns the transactions after the one with the given ID, only
+// those of the given types if any filters are given
+func (c *Connection) GetTransactionsSinceId(id string, filter ...TransactionFilter) (Transactions, error) {
+	return c.GetTransactionsSinceIdContext(context.Background(), id, filter...)
+}
+
+// GetTransactionsSinceIdContext is GetTransactionsSinceId bound to the given context
+func (c *Connection) GetTransactionsSinceIdContext(ctx context.Context, id string, filter ...TransactionFilter) (Transactions, error) {
+	return c.getTransactionsSinceID(ctx, id, joinFilters(filter))
+}
+
+func (c *Connection) getTransactionsSinceID(ctx context.Context, id string, types string) (Transactions, error) {
+	v := url.Values{}
+	v.Set("id", id)
+	if types != "" {
+		v.Set("type", types)
+	}
+
 	tr := Transactions{}
-	err := c.getAndUnmarshal(
-		"/accounts/"+
-			c.accountID+
-			"/transactions/sinceid?id="+
-			id,
-		&tr,
-	)
+	err := c.getAndUnmarshal(ctx, "/accounts/"+c.accountID+"/transactions/sinceid?"+v.Encode(), &tr)
 	return tr, err
 }
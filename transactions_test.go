@@ -1,9 +1,15 @@
 package goanda
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -74,32 +80,14 @@ func TestGetTransaction(t *testing.T) {
 
 		response := Transaction{
 			LastTransactionID: "1000",
-			Transaction: struct {
-				AccountBalance string    `json:"accountBalance"`
-				AccountID      string    `json:"accountID"`
-				BatchID        string    `json:"batchID"`
-				Financing      string    `json:"financing"`
-				ID             string    `json:"id"`
-				Instrument     string    `json:"instrument"`
-				OrderID        string    `json:"orderID"`
-				Pl             string    `json:"pl"`
-				Price          string    `json:"price"`
-				Reason         string    `json:"reason"`
-				Time           time.Time `json:"time"`
-				TradeOpened    struct {
-					TradeID string `json:"tradeID"`
-					Units   string `json:"units"`
-				} `json:"tradeOpened"`
-				Type   string `json:"type"`
-				Units  string `json:"units"`
-				UserID int    `json:"userID"`
-			}{
-				ID:         "1000",
-				Type:       "MARKET_ORDER",
+			Transaction: AnyTransaction{&MarketOrderTransaction{
+				TransactionBase: TransactionBase{
+					ID:   "1000",
+					Type: "MARKET_ORDER",
+				},
 				Instrument: "EUR_USD",
 				Units:      "100",
-				Price:      "1.1000",
-			},
+			}},
 		}
 		json.NewEncoder(w).Encode(response)
 	}))
@@ -120,12 +108,16 @@ func TestGetTransaction(t *testing.T) {
 		t.Errorf("Expected LastTransactionID to be 1000, got %s", transaction.LastTransactionID)
 	}
 
-	if transaction.Transaction.ID != "1000" {
-		t.Errorf("Expected Transaction.ID to be 1000, got %s", transaction.Transaction.ID)
+	if transaction.Transaction.Base().ID != "1000" {
+		t.Errorf("Expected Transaction.ID to be 1000, got %s", transaction.Transaction.Base().ID)
 	}
 
-	if transaction.Transaction.Type != "MARKET_ORDER" {
-		t.Errorf("Expected Transaction.Type to be MARKET_ORDER, got %s", transaction.Transaction.Type)
+	order, ok := transaction.Transaction.TypedTransaction.(*MarketOrderTransaction)
+	if !ok {
+		t.Fatalf("Expected a *MarketOrderTransaction, got %T", transaction.Transaction.TypedTransaction)
+	}
+	if order.Instrument != "EUR_USD" || order.Units != "100" {
+		t.Errorf("Unexpected market order: %+v", order)
 	}
 }
 
@@ -148,40 +140,18 @@ func TestGetTransactionsSinceId(t *testing.T) {
 
 		response := Transactions{
 			LastTransactionID: "1001",
-			Transactions: []struct {
-				AccountBalance string    `json:"accountBalance"`
-				AccountID      string    `json:"accountID"`
-				BatchID        string    `json:"batchID"`
-				Financing      string    `json:"financing"`
-				ID             string    `json:"id"`
-				Instrument     string    `json:"instrument"`
-				OrderID        string    `json:"orderID"`
-				Pl             string    `json:"pl"`
-				Price          string    `json:"price"`
-				Reason         string    `json:"reason"`
-				Time           time.Time `json:"time"`
-				TradeOpened    struct {
-					TradeID string `json:"tradeID"`
-					Units   string `json:"units"`
-				} `json:"tradeOpened"`
-				Type   string `json:"type"`
-				Units  string `json:"units"`
-				UserID int    `json:"userID"`
-			}{
-				{
-					ID:         "1000",
-					Type:       "MARKET_ORDER",
-					Instrument: "EUR_USD",
-					Units:      "100",
-					Price:      "1.1000",
-				},
-				{
-					ID:         "1001",
-					Type:       "TRADE_CLOSE",
-					Instrument: "EUR_USD",
-					Units:      "-100",
-					Price:      "1.1010",
-				},
+			Transactions: []AnyTransaction{
+				{&MarketOrderTransaction{
+					TransactionBase: TransactionBase{ID: "1000", Type: "MARKET_ORDER"},
+					Instrument:      "EUR_USD",
+					Units:           "100",
+				}},
+				{&OrderFillTransaction{
+					TransactionBase: TransactionBase{ID: "1001", Type: "ORDER_FILL"},
+					Instrument:      "EUR_USD",
+					Units:           "100",
+					Price:           "1.1010",
+				}},
 			},
 		}
 		json.NewEncoder(w).Encode(response)
@@ -207,11 +177,180 @@ func TestGetTransactionsSinceId(t *testing.T) {
 		t.Fatalf("Expected 2 transactions, got %d", len(transactions.Transactions))
 	}
 
-	if transactions.Transactions[0].ID != "1000" {
-		t.Errorf("Expected first transaction ID to be 1000, got %s", transactions.Transactions[0].ID)
+	if transactions.Transactions[0].Base().ID != "1000" {
+		t.Errorf("Expected first transaction ID to be 1000, got %s", transactions.Transactions[0].Base().ID)
+	}
+
+	fill, ok := transactions.Transactions[1].TypedTransaction.(*OrderFillTransaction)
+	if !ok {
+		t.Fatalf("Expected an *OrderFillTransaction, got %T", transactions.Transactions[1].TypedTransaction)
+	}
+	if fill.ID != "1001" || fill.Price != "1.1010" {
+		t.Errorf("Unexpected order fill: %+v", fill)
+	}
+}
+
+// transactionRangeServer serves transactions 1 to last, in pages of pageSize, through
+// the transactions, idrange and sinceid endpoints
+func transactionRangeServer(t *testing.T, last int, pageSize int, sinceLimit int) *httptest.Server {
+	write := func(w http.ResponseWriter, from int, to int) {
+		if to > last {
+			to = last
+		}
+		var transactions []AnyTransaction
+		for id := from; id <= to; id++ {
+			transactions = append(transactions, AnyTransaction{&OrderFillTransaction{
+				TransactionBase: TransactionBase{ID: strconv.Itoa(id), Type: "ORDER_FILL"},
+			}})
+		}
+		json.NewEncoder(w).Encode(Transactions{LastTransactionID: strconv.Itoa(last), Transactions: transactions})
 	}
 
-	if transactions.Transactions[1].ID != "1001" {
-		t.Errorf("Expected second transaction ID to be 1001, got %s", transactions.Transactions[1].ID)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/accounts/test-account/transactions":
+			if q.Get("type") != "ORDER_FILL,DAILY_FINANCING" {
+				t.Errorf("Unexpected type filter: %s", q.Get("type"))
+			}
+			var pages []string
+			for from := 1; from <= last; from += pageSize {
+				// The api lists pages on its own host, which must not be followed
+				pages = append(pages, fmt.Sprintf("https://api-fxpractice.oanda.com/v3/accounts/test-account/transactions/idrange?from=%d&to=%d&type=%s",
+					from, from+pageSize-1, url.QueryEscape(q.Get("type"))))
+			}
+			json.NewEncoder(w).Encode(TransactionPages{Pages: pages, Count: last, PageSize: pageSize})
+		case "/accounts/test-account/transactions/idrange":
+			if q.Get("type") != "ORDER_FILL,DAILY_FINANCING" {
+				t.Errorf("Unexpected type filter: %s", q.Get("type"))
+			}
+			from, _ := strconv.Atoi(q.Get("from"))
+			to, _ := strconv.Atoi(q.Get("to"))
+			// Later pages answer first, to check the order is kept
+			time.Sleep(time.Duration(last-from) * time.Millisecond / 4)
+			write(w, from, to)
+		case "/accounts/test-account/transactions/sinceid":
+			id, _ := strconv.Atoi(q.Get("id"))
+			write(w, id+1, id+sinceLimit)
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestWalkTransactions(t *testing.T) {
+	defer logTestResult(t, "WalkTransactions")
+
+	server := transactionRangeServer(t, 95, 10, 0)
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	var ids []string
+	err := c.WalkTransactions(context.Background(), TransactionQuery{
+		From:        time.Now().Add(-24 * time.Hour),
+		Type:        []TransactionFilter{TransactionFilterOrderFill, TransactionFilterDailyFinancing},
+		Concurrency: 3,
+	}, func(tx TypedTransaction) error {
+		ids = append(ids, tx.Base().ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(ids) != 95 {
+		t.Fatalf("Expected 95 transactions, got %d", len(ids))
+	}
+	for i, id := range ids {
+		if id != strconv.Itoa(i+1) {
+			t.Fatalf("Expected transaction %d at %d, got %s", i+1, i, id)
+		}
+	}
+
+	stop := errors.New("stop")
+	var seen int
+	err = c.WalkTransactions(context.Background(), TransactionQuery{
+		Type: []TransactionFilter{TransactionFilterOrderFill, TransactionFilterDailyFinancing},
+	}, func(tx TypedTransaction) error {
+		seen++
+		if seen == 15 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("Expected the callback's error, got %v", err)
+	}
+}
+
+func TestWalkTransactionsSinceID(t *testing.T) {
+	defer logTestResult(t, "WalkTransactionsSinceID")
+
+	server := transactionRangeServer(t, 25, 10, 10)
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	var ids []string
+	err := c.WalkTransactions(context.Background(), TransactionQuery{SinceID: "3"}, func(tx TypedTransaction) error {
+		ids = append(ids, tx.Base().ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(ids) != 22 || ids[0] != "4" || ids[21] != "25" {
+		t.Errorf("Expected transactions 4 to 25, got %v", ids)
+	}
+}
+
+func TestTransactionFilters(t *testing.T) {
+	defer logTestResult(t, "TransactionFilters")
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Path+" type="+r.URL.Query().Get("type"))
+		w.Write([]byte(`{"lastTransactionID":"10"}`))
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	if _, err := c.GetTransactionsSinceId("5", TransactionFilterDailyFinancing); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.GetTransactionIDRange("1", "5", TransactionFilterOrder, TransactionFilterFunding); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.GetTransactions(time.Now().Add(-time.Hour), time.Now(), TransactionFilterAdmin); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.GetTransactionsSinceId("5"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"/accounts/test-account/transactions/sinceid type=DAILY_FINANCING",
+		"/accounts/test-account/transactions/idrange type=ORDER,FUNDING",
+		"/accounts/test-account/transactions type=ADMIN",
+		"/accounts/test-account/transactions/sinceid type=",
+	}
+	if strings.Join(queries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected queries:\n%s", strings.Join(queries, "\n"))
 	}
 }
@@ -0,0 +1,579 @@
+package goanda
+
+// Supporting OANDA docs - http://developer.oanda.com/rest-live-v20/transaction-df/
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TransactionType is the type of a transaction, which decides its concrete Go type
+type TransactionType string
+
+const (
+	TransactionTypeCreate                            TransactionType = "CREATE"
+	TransactionTypeClose                             TransactionType = "CLOSE"
+	TransactionTypeReopen                            TransactionType = "REOPEN"
+	TransactionTypeClientConfigure                   TransactionType = "CLIENT_CONFIGURE"
+	TransactionTypeClientConfigureReject             TransactionType = "CLIENT_CONFIGURE_REJECT"
+	TransactionTypeTransferFunds                     TransactionType = "TRANSFER_FUNDS"
+	TransactionTypeTransferFundsReject               TransactionType = "TRANSFER_FUNDS_REJECT"
+	TransactionTypeMarketOrder                       TransactionType = "MARKET_ORDER"
+	TransactionTypeMarketOrderReject                 TransactionType = "MARKET_ORDER_REJECT"
+	TransactionTypeFixedPriceOrder                   TransactionType = "FIXED_PRICE_ORDER"
+	TransactionTypeLimitOrder                        TransactionType = "LIMIT_ORDER"
+	TransactionTypeLimitOrderReject                  TransactionType = "LIMIT_ORDER_REJECT"
+	TransactionTypeStopOrder                         TransactionType = "STOP_ORDER"
+	TransactionTypeStopOrderReject                   TransactionType = "STOP_ORDER_REJECT"
+	TransactionTypeMarketIfTouchedOrder              TransactionType = "MARKET_IF_TOUCHED_ORDER"
+	TransactionTypeMarketIfTouchedOrderReject        TransactionType = "MARKET_IF_TOUCHED_ORDER_REJECT"
+	TransactionTypeTakeProfitOrder                   TransactionType = "TAKE_PROFIT_ORDER"
+	TransactionTypeTakeProfitOrderReject             TransactionType = "TAKE_PROFIT_ORDER_REJECT"
+	TransactionTypeStopLossOrder                     TransactionType = "STOP_LOSS_ORDER"
+	TransactionTypeStopLossOrderReject               TransactionType = "STOP_LOSS_ORDER_REJECT"
+	TransactionTypeGuaranteedStopLossOrder           TransactionType = "GUARANTEED_STOP_LOSS_ORDER"
+	TransactionTypeGuaranteedStopLossOrderReject     TransactionType = "GUARANTEED_STOP_LOSS_ORDER_REJECT"
+	TransactionTypeTrailingStopLossOrder             TransactionType = "TRAILING_STOP_LOSS_ORDER"
+	TransactionTypeTrailingStopLossOrderReject       TransactionType = "TRAILING_STOP_LOSS_ORDER_REJECT"
+	TransactionTypeOrderFill                         TransactionType = "ORDER_FILL"
+	TransactionTypeOrderCancel                       TransactionType = "ORDER_CANCEL"
+	TransactionTypeOrderCancelReject                 TransactionType = "ORDER_CANCEL_REJECT"
+	TransactionTypeOrderClientExtensionsModify       TransactionType = "ORDER_CLIENT_EXTENSIONS_MODIFY"
+	TransactionTypeOrderClientExtensionsModifyReject TransactionType = "ORDER_CLIENT_EXTENSIONS_MODIFY_REJECT"
+	TransactionTypeTradeClientExtensionsModify       TransactionType = "TRADE_CLIENT_EXTENSIONS_MODIFY"
+	TransactionTypeTradeClientExtensionsModifyReject TransactionType = "TRADE_CLIENT_EXTENSIONS_MODIFY_REJECT"
+	TransactionTypeMarginCallEnter                   TransactionType = "MARGIN_CALL_ENTER"
+	TransactionTypeMarginCallExtend                  TransactionType = "MARGIN_CALL_EXTEND"
+	TransactionTypeMarginCallExit                    TransactionType = "MARGIN_CALL_EXIT"
+	TransactionTypeDelayedTradeClosure               TransactionType = "DELAYED_TRADE_CLOSURE"
+	TransactionTypeDailyFinancing                    TransactionType = "DAILY_FINANCING"
+	TransactionTypeDividendAdjustment                TransactionType = "DIVIDEND_ADJUSTMENT"
+	TransactionTypeResetResettablePL                 TransactionType = "RESET_RESETTABLE_PL"
+)
+
+// TypedTransaction is implemented by every transaction type. Use a type switch to get
+// at the fields of a particular type:
+//
+//	switch tx := t.(type) {
+//	case *goanda.OrderFillTransaction:
+//		...
+//	case *goanda.OrderCancelTransaction:
+//		...
+//	}
+type TypedTransaction interface {
+	// Base returns the fields common to every transaction
+	Base() TransactionBase
+}
+
+var transactionTypes = map[TransactionType]func() TypedTransaction{
+	TransactionTypeCreate:                            func() TypedTransaction { return &CreateTransaction{} },
+	TransactionTypeClose:                             func() TypedTransaction { return &CloseTransaction{} },
+	TransactionTypeReopen:                            func() TypedTransaction { return &ReopenTransaction{} },
+	TransactionTypeClientConfigure:                   func() TypedTransaction { return &ClientConfigureTransaction{} },
+	TransactionTypeClientConfigureReject:             func() TypedTransaction { return &ClientConfigureRejectTransaction{} },
+	TransactionTypeTransferFunds:                     func() TypedTransaction { return &TransferFundsTransaction{} },
+	TransactionTypeTransferFundsReject:               func() TypedTransaction { return &TransferFundsRejectTransaction{} },
+	TransactionTypeMarketOrder:                       func() TypedTransaction { return &MarketOrderTransaction{} },
+	TransactionTypeMarketOrderReject:                 func() TypedTransaction { return &MarketOrderRejectTransaction{} },
+	TransactionTypeFixedPriceOrder:                   func() TypedTransaction { return &FixedPriceOrderTransaction{} },
+	TransactionTypeLimitOrder:                        func() TypedTransaction { return &LimitOrderTransaction{} },
+	TransactionTypeLimitOrderReject:                  func() TypedTransaction { return &LimitOrderRejectTransaction{} },
+	TransactionTypeStopOrder:                         func() TypedTransaction { return &StopOrderTransaction{} },
+	TransactionTypeStopOrderReject:                   func() TypedTransaction { return &StopOrderRejectTransaction{} },
+	TransactionTypeMarketIfTouchedOrder:              func() TypedTransaction { return &MarketIfTouchedOrderTransaction{} },
+	TransactionTypeMarketIfTouchedOrderReject:        func() TypedTransaction { return &MarketIfTouchedOrderRejectTransaction{} },
+	TransactionTypeTakeProfitOrder:                   func() TypedTransaction { return &TakeProfitOrderTransaction{} },
+	TransactionTypeTakeProfitOrderReject:             func() TypedTransaction { return &TakeProfitOrderRejectTransaction{} },
+	TransactionTypeStopLossOrder:                     func() TypedTransaction { return &StopLossOrderTransaction{} },
+	TransactionTypeStopLossOrderReject:               func() TypedTransaction { return &StopLossOrderRejectTransaction{} },
+	TransactionTypeGuaranteedStopLossOrder:           func() TypedTransaction { return &GuaranteedStopLossOrderTransaction{} },
+	TransactionTypeGuaranteedStopLossOrderReject:     func() TypedTransaction { return &GuaranteedStopLossOrderRejectTransaction{} },
+	TransactionTypeTrailingStopLossOrder:             func() TypedTransaction { return &TrailingStopLossOrderTransaction{} },
+	TransactionTypeTrailingStopLossOrderReject:       func() TypedTransaction { return &TrailingStopLossOrderRejectTransaction{} },
+	TransactionTypeOrderFill:                         func() TypedTransaction { return &OrderFillTransaction{} },
+	TransactionTypeOrderCancel:                       func() TypedTransaction { return &OrderCancelTransaction{} },
+	TransactionTypeOrderCancelReject:                 func() TypedTransaction { return &OrderCancelRejectTransaction{} },
+	TransactionTypeOrderClientExtensionsModify:       func() TypedTransaction { return &OrderClientExtensionsModifyTransaction{} },
+	TransactionTypeOrderClientExtensionsModifyReject: func() TypedTransaction { return &OrderClientExtensionsModifyRejectTransaction{} },
+	TransactionTypeTradeClientExtensionsModify:       func() TypedTransaction { return &TradeClientExtensionsModifyTransaction{} },
+	TransactionTypeTradeClientExtensionsModifyReject: func() TypedTransaction { return &TradeClientExtensionsModifyRejectTransaction{} },
+	TransactionTypeMarginCallEnter:                   func() TypedTransaction { return &MarginCallEnterTransaction{} },
+	TransactionTypeMarginCallExtend:                  func() TypedTransaction { return &MarginCallExtendTransaction{} },
+	TransactionTypeMarginCallExit:                    func() TypedTransaction { return &MarginCallExitTransaction{} },
+	TransactionTypeDelayedTradeClosure:               func() TypedTransaction { return &DelayedTradeClosureTransaction{} },
+	TransactionTypeDailyFinancing:                    func() TypedTransaction { return &DailyFinancingTransaction{} },
+	TransactionTypeDividendAdjustment:                func() TypedTransaction { return &DividendAdjustmentTransaction{} },
+	TransactionTypeResetResettablePL:                 func() TypedTransaction { return &ResetResettablePLTransaction{} },
+}
+
+// DecodeTransaction decodes a transaction into a pointer to the concrete type given by
+// its type field, or an *UnknownTransaction for types this package doesn't know
+func DecodeTransaction(data []byte) (TypedTransaction, error) {
+	var base TransactionBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+
+	newTransaction, ok := transactionTypes[base.Type]
+	if !ok {
+		raw := make(json.RawMessage, len(data))
+		copy(raw, data)
+		return &UnknownTransaction{TransactionBase: base, Raw: raw}, nil
+	}
+
+	t := newTransaction()
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("goanda: decoding %s transaction: %w", base.Type, err)
+	}
+	return t, nil
+}
+
+// AnyTransaction holds a transaction of any type in a response, decoded with DecodeTransaction
+type AnyTransaction struct {
+	TypedTransaction
+}
+
+// UnmarshalJSON decodes the transaction into its concrete type. A null transaction is left nil.
+func (a *AnyTransaction) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		a.TypedTransaction = nil
+		return nil
+	}
+
+	t, err := DecodeTransaction(data)
+	if err != nil {
+		return err
+	}
+	a.TypedTransaction = t
+	return nil
+}
+
+// MarshalJSON encodes the concrete transaction
+func (a AnyTransaction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.TypedTransaction)
+}
+
+// TransactionBase holds the fields common to every transaction
+type TransactionBase struct {
+	ID        string          `json:"id"`
+	Time      time.Time       `json:"time"`
+	UserID    int             `json:"userID"`
+	AccountID string          `json:"accountID"`
+	BatchID   string          `json:"batchID"`
+	RequestID string          `json:"requestID,omitempty"`
+	Type      TransactionType `json:"type"`
+}
+
+// Base implements TypedTransaction
+func (t TransactionBase) Base() TransactionBase {
+	return t
+}
+
+// UnknownTransaction is a transaction of a type this package doesn't know. Raw holds
+// the whole transaction as sent by the api.
+type UnknownTransaction struct {
+	TransactionBase
+	Raw json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the transaction as it was received
+func (t UnknownTransaction) MarshalJSON() ([]byte, error) {
+	if t.Raw != nil {
+		return t.Raw, nil
+	}
+	return json.Marshal(t.TransactionBase)
+}
+
+// CreateTransaction records an account being created
+type CreateTransaction struct {
+	TransactionBase
+	DivisionID    int    `json:"divisionID"`
+	SiteID        int    `json:"siteID"`
+	AccountUserID int    `json:"accountUserID"`
+	AccountNumber int    `json:"accountNumber"`
+	HomeCurrency  string `json:"homeCurrency"`
+}
+
+// CloseTransaction records an account being closed
+type CloseTransaction struct {
+	TransactionBase
+}
+
+// ReopenTransaction records a closed account being reopened
+type ReopenTransaction struct {
+	TransactionBase
+}
+
+// ClientConfigureTransaction records the account's alias or margin rate being changed
+type ClientConfigureTransaction struct {
+	TransactionBase
+	Alias      string  `json:"alias,omitempty"`
+	MarginRate Decimal `json:"marginRate,omitempty"`
+}
+
+// ClientConfigureRejectTransaction records a rejected change to the account's configuration
+type ClientConfigureRejectTransaction struct {
+	ClientConfigureTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// TransferFundsTransaction records funds being deposited into or withdrawn from the account
+type TransferFundsTransaction struct {
+	TransactionBase
+	Amount         Decimal `json:"amount"`
+	FundingReason  string  `json:"fundingReason"`
+	Comment        string  `json:"comment,omitempty"`
+	AccountBalance Decimal `json:"accountBalance"`
+}
+
+// TransferFundsRejectTransaction records a rejected transfer of funds
+type TransferFundsRejectTransaction struct {
+	TransactionBase
+	Amount        Decimal      `json:"amount"`
+	FundingReason string       `json:"fundingReason"`
+	Comment       string       `json:"comment,omitempty"`
+	RejectReason  RejectReason `json:"rejectReason"`
+}
+
+// OrderFillTransaction records an order being filled
+type OrderFillTransaction struct {
+	TransactionBase
+	OrderID        string        `json:"orderID"`
+	ClientOrderID  string        `json:"clientOrderID,omitempty"`
+	Instrument     string        `json:"instrument"`
+	Units          Decimal       `json:"units"`
+	Price          Decimal       `json:"price"`
+	FullVWAP       Decimal       `json:"fullVWAP,omitempty"`
+	FullPrice      FullPrice     `json:"fullPrice"`
+	Reason         string        `json:"reason"`
+	PL             Decimal       `json:"pl"`
+	QuotePL        Decimal       `json:"quotePL,omitempty"`
+	Financing      Decimal       `json:"financing"`
+	Commission     Decimal       `json:"commission"`
+	AccountBalance Decimal       `json:"accountBalance"`
+	HalfSpreadCost Decimal       `json:"halfSpreadCost,omitempty"`
+	TradeOpened    *TradeOpen    `json:"tradeOpened,omitempty"`
+	TradesClosed   []TradeReduce `json:"tradesClosed,omitempty"`
+	TradeReduced   *TradeReduce  `json:"tradeReduced,omitempty"`
+}
+
+// TradeOpen is the trade opened by an order fill
+type TradeOpen struct {
+	TradeID                string           `json:"tradeID"`
+	Units                  Decimal          `json:"units"`
+	Price                  Decimal          `json:"price"`
+	GuaranteedExecutionFee Decimal          `json:"guaranteedExecutionFee,omitempty"`
+	HalfSpreadCost         Decimal          `json:"halfSpreadCost,omitempty"`
+	InitialMarginRequired  Decimal          `json:"initialMarginRequired,omitempty"`
+	ClientExtensions       *OrderExtensions `json:"clientExtensions,omitempty"`
+}
+
+// TradeReduce is a trade closed or reduced by an order fill
+type TradeReduce struct {
+	TradeID                string  `json:"tradeID"`
+	Units                  Decimal `json:"units"`
+	Price                  Decimal `json:"price"`
+	RealizedPL             Decimal `json:"realizedPL"`
+	Financing              Decimal `json:"financing"`
+	GuaranteedExecutionFee Decimal `json:"guaranteedExecutionFee,omitempty"`
+	HalfSpreadCost         Decimal `json:"halfSpreadCost,omitempty"`
+}
+
+// MarketOrderTransaction records a market order being created, including those
+// created to close a trade or position
+type MarketOrderTransaction struct {
+	TransactionBase
+	Instrument               string                 `json:"instrument"`
+	Units                    Decimal                `json:"units"`
+	TimeInForce              TimeInForce            `json:"timeInForce"`
+	PriceBound               Decimal                `json:"priceBound,omitempty"`
+	PositionFill             PositionFill           `json:"positionFill"`
+	TradeClose               *MarketOrderTradeClose `json:"tradeClose,omitempty"`
+	LongPositionCloseout     *MarketOrderCloseout   `json:"longPositionCloseout,omitempty"`
+	ShortPositionCloseout    *MarketOrderCloseout   `json:"shortPositionCloseout,omitempty"`
+	Reason                   string                 `json:"reason"`
+	ClientExtensions         *OrderExtensions       `json:"clientExtensions,omitempty"`
+	TakeProfitOnFill         *OnFill                `json:"takeProfitOnFill,omitempty"`
+	StopLossOnFill           *OnFill                `json:"stopLossOnFill,omitempty"`
+	GuaranteedStopLossOnFill *OnFill                `json:"guaranteedStopLossOnFill,omitempty"`
+	TrailingStopLossOnFill   *OnFill                `json:"trailingStopLossOnFill,omitempty"`
+	TradeClientExtensions    *OrderExtensions       `json:"tradeClientExtensions,omitempty"`
+}
+
+// MarketOrderRejectTransaction records a rejected market order
+type MarketOrderRejectTransaction struct {
+	MarketOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// FixedPriceOrderTransaction records an order filled at a fixed price being created
+type FixedPriceOrderTransaction struct {
+	TransactionBase
+	Instrument               string           `json:"instrument"`
+	Units                    Decimal          `json:"units"`
+	Price                    Decimal          `json:"price"`
+	PositionFill             PositionFill     `json:"positionFill"`
+	TradeState               string           `json:"tradeState"`
+	Reason                   string           `json:"reason"`
+	ClientExtensions         *OrderExtensions `json:"clientExtensions,omitempty"`
+	TakeProfitOnFill         *OnFill          `json:"takeProfitOnFill,omitempty"`
+	StopLossOnFill           *OnFill          `json:"stopLossOnFill,omitempty"`
+	GuaranteedStopLossOnFill *OnFill          `json:"guaranteedStopLossOnFill,omitempty"`
+	TrailingStopLossOnFill   *OnFill          `json:"trailingStopLossOnFill,omitempty"`
+	TradeClientExtensions    *OrderExtensions `json:"tradeClientExtensions,omitempty"`
+}
+
+// PendingOrderTransaction holds the fields shared by the transactions creating limit,
+// stop and market if touched orders
+type PendingOrderTransaction struct {
+	TransactionBase
+	Instrument               string           `json:"instrument"`
+	Units                    Decimal          `json:"units"`
+	Price                    Decimal          `json:"price"`
+	TimeInForce              TimeInForce      `json:"timeInForce"`
+	GtdTime                  string           `json:"gtdTime,omitempty"`
+	PositionFill             PositionFill     `json:"positionFill"`
+	TriggerCondition         TriggerCondition `json:"triggerCondition"`
+	Reason                   string           `json:"reason"`
+	ClientExtensions         *OrderExtensions `json:"clientExtensions,omitempty"`
+	TakeProfitOnFill         *OnFill          `json:"takeProfitOnFill,omitempty"`
+	StopLossOnFill           *OnFill          `json:"stopLossOnFill,omitempty"`
+	GuaranteedStopLossOnFill *OnFill          `json:"guaranteedStopLossOnFill,omitempty"`
+	TrailingStopLossOnFill   *OnFill          `json:"trailingStopLossOnFill,omitempty"`
+	TradeClientExtensions    *OrderExtensions `json:"tradeClientExtensions,omitempty"`
+	ReplacesOrderID          string           `json:"replacesOrderID,omitempty"`
+	CancellingTransactionID  string           `json:"cancellingTransactionID,omitempty"`
+}
+
+// LimitOrderTransaction records a limit order being created
+type LimitOrderTransaction struct {
+	PendingOrderTransaction
+}
+
+// LimitOrderRejectTransaction records a rejected limit order
+type LimitOrderRejectTransaction struct {
+	PendingOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// StopOrderTransaction records a stop order being created
+type StopOrderTransaction struct {
+	PendingOrderTransaction
+	PriceBound Decimal `json:"priceBound,omitempty"`
+}
+
+// StopOrderRejectTransaction records a rejected stop order
+type StopOrderRejectTransaction struct {
+	StopOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// MarketIfTouchedOrderTransaction records a market if touched order being created
+type MarketIfTouchedOrderTransaction struct {
+	PendingOrderTransaction
+	PriceBound Decimal `json:"priceBound,omitempty"`
+}
+
+// MarketIfTouchedOrderRejectTransaction records a rejected market if touched order
+type MarketIfTouchedOrderRejectTransaction struct {
+	MarketIfTouchedOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// MarketOrderTradeClose is the trade a market order was created to close
+type MarketOrderTradeClose struct {
+	TradeID       string `json:"tradeID"`
+	ClientTradeID string `json:"clientTradeID,omitempty"`
+	Units         string `json:"units"`
+}
+
+// MarketOrderCloseout is the side of a position a market order was created to close
+type MarketOrderCloseout struct {
+	Instrument string `json:"instrument"`
+	Units      string `json:"units"`
+}
+
+// OrderCancelTransaction records an order being cancelled
+type OrderCancelTransaction struct {
+	TransactionBase
+	OrderID           string `json:"orderID"`
+	ClientOrderID     string `json:"clientOrderID,omitempty"`
+	Reason            string `json:"reason"`
+	ReplacedByOrderID string `json:"replacedByOrderID,omitempty"`
+}
+
+// DependentOrderTransaction holds the fields shared by the transactions creating orders
+// that close a trade
+type DependentOrderTransaction struct {
+	TransactionBase
+	TradeID                 string           `json:"tradeID"`
+	ClientTradeID           string           `json:"clientTradeID,omitempty"`
+	TimeInForce             TimeInForce      `json:"timeInForce"`
+	GtdTime                 string           `json:"gtdTime,omitempty"`
+	TriggerCondition        TriggerCondition `json:"triggerCondition"`
+	Reason                  string           `json:"reason"`
+	ClientExtensions        *OrderExtensions `json:"clientExtensions,omitempty"`
+	OrderFillTransactionID  string           `json:"orderFillTransactionID,omitempty"`
+	ReplacesOrderID         string           `json:"replacesOrderID,omitempty"`
+	CancellingTransactionID string           `json:"cancellingTransactionID,omitempty"`
+}
+
+// TakeProfitOrderTransaction records a take profit order being created
+type TakeProfitOrderTransaction struct {
+	DependentOrderTransaction
+	Price Decimal `json:"price"`
+}
+
+// StopLossOrderTransaction records a stop loss order being created
+type StopLossOrderTransaction struct {
+	DependentOrderTransaction
+	Price    Decimal `json:"price,omitempty"`
+	Distance Decimal `json:"distance,omitempty"`
+}
+
+// GuaranteedStopLossOrderTransaction records a guaranteed stop loss order being created
+type GuaranteedStopLossOrderTransaction struct {
+	DependentOrderTransaction
+	Price                      Decimal `json:"price,omitempty"`
+	Distance                   Decimal `json:"distance,omitempty"`
+	GuaranteedExecutionPremium Decimal `json:"guaranteedExecutionPremium,omitempty"`
+}
+
+// TrailingStopLossOrderTransaction records a trailing stop loss order being created
+type TrailingStopLossOrderTransaction struct {
+	DependentOrderTransaction
+	Distance Decimal `json:"distance"`
+}
+
+// TakeProfitOrderRejectTransaction records a rejected take profit order
+type TakeProfitOrderRejectTransaction struct {
+	TakeProfitOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// StopLossOrderRejectTransaction records a rejected stop loss order
+type StopLossOrderRejectTransaction struct {
+	StopLossOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// GuaranteedStopLossOrderRejectTransaction records a rejected guaranteed stop loss order
+type GuaranteedStopLossOrderRejectTransaction struct {
+	GuaranteedStopLossOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// TrailingStopLossOrderRejectTransaction records a rejected trailing stop loss order
+type TrailingStopLossOrderRejectTransaction struct {
+	TrailingStopLossOrderTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// OrderCancelRejectTransaction records a rejected request to cancel an order
+type OrderCancelRejectTransaction struct {
+	TransactionBase
+	OrderID       string       `json:"orderID"`
+	ClientOrderID string       `json:"clientOrderID,omitempty"`
+	RejectReason  RejectReason `json:"rejectReason"`
+}
+
+// OrderClientExtensionsModifyTransaction records the client extensions of an order,
+// or of the trade it will open, being changed
+type OrderClientExtensionsModifyTransaction struct {
+	TransactionBase
+	OrderID                     string           `json:"orderID"`
+	ClientOrderID               string           `json:"clientOrderID,omitempty"`
+	ClientExtensionsModify      *OrderExtensions `json:"clientExtensionsModify,omitempty"`
+	TradeClientExtensionsModify *OrderExtensions `json:"tradeClientExtensionsModify,omitempty"`
+}
+
+// TradeClientExtensionsModifyTransaction records the client extensions of a trade being changed
+type TradeClientExtensionsModifyTransaction struct {
+	TransactionBase
+	TradeID                     string           `json:"tradeID"`
+	ClientTradeID               string           `json:"clientTradeID,omitempty"`
+	TradeClientExtensionsModify *OrderExtensions `json:"tradeClientExtensionsModify,omitempty"`
+}
+
+// OrderClientExtensionsModifyRejectTransaction records a rejected change to an order's
+// client extensions
+type OrderClientExtensionsModifyRejectTransaction struct {
+	OrderClientExtensionsModifyTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// TradeClientExtensionsModifyRejectTransaction records a rejected change to a trade's
+// client extensions
+type TradeClientExtensionsModifyRejectTransaction struct {
+	TradeClientExtensionsModifyTransaction
+	RejectReason RejectReason `json:"rejectReason"`
+}
+
+// MarginCallEnterTransaction records the account entering a margin call state
+type MarginCallEnterTransaction struct {
+	TransactionBase
+}
+
+// MarginCallExtendTransaction records the account's margin call state being extended
+type MarginCallExtendTransaction struct {
+	TransactionBase
+	ExtensionNumber int `json:"extensionNumber"`
+}
+
+// MarginCallExitTransaction records the account leaving a margin call state
+type MarginCallExitTransaction struct {
+	TransactionBase
+}
+
+// DelayedTradeClosureTransaction records trades that will be closed once their
+// instrument is tradeable again
+type DelayedTradeClosureTransaction struct {
+	TransactionBase
+	Reason   string `json:"reason"`
+	TradeIDs string `json:"tradeIDs"`
+}
+
+// DailyFinancingTransaction records the daily financing charged or credited to the account
+type DailyFinancingTransaction struct {
+	TransactionBase
+	Financing            Decimal             `json:"financing"`
+	AccountBalance       Decimal             `json:"accountBalance"`
+	AccountFinancingMode string              `json:"accountFinancingMode,omitempty"`
+	PositionFinancings   []PositionFinancing `json:"positionFinancings,omitempty"`
+}
+
+// PositionFinancing is the financing of a position, and of each of its open trades
+type PositionFinancing struct {
+	Instrument          string               `json:"instrument"`
+	Financing           Decimal              `json:"financing"`
+	OpenTradeFinancings []OpenTradeFinancing `json:"openTradeFinancings,omitempty"`
+}
+
+// OpenTradeFinancing is the financing of an open trade
+type OpenTradeFinancing struct {
+	TradeID   string  `json:"tradeID"`
+	Financing Decimal `json:"financing"`
+}
+
+// DividendAdjustmentTransaction records a dividend adjustment to the account's open trades
+type DividendAdjustmentTransaction struct {
+	TransactionBase
+	Instrument                   string                        `json:"instrument"`
+	DividendAdjustment           Decimal                       `json:"dividendAdjustment"`
+	QuoteDividendAdjustment      Decimal                       `json:"quoteDividendAdjustment,omitempty"`
+	AccountBalance               Decimal                       `json:"accountBalance"`
+	OpenTradeDividendAdjustments []OpenTradeDividendAdjustment `json:"openTradeDividendAdjustments,omitempty"`
+}
+
+// OpenTradeDividendAdjustment is the dividend adjustment of an open trade
+type OpenTradeDividendAdjustment struct {
+	TradeID                 string  `json:"tradeID"`
+	DividendAdjustment      Decimal `json:"dividendAdjustment"`
+	QuoteDividendAdjustment Decimal `json:"quoteDividendAdjustment,omitempty"`
+}
+
+// ResetResettablePLTransaction records the account's resettable PL being reset to zero
+type ResetResettablePLTransaction struct {
+	TransactionBase
+}
@@ -0,0 +1,108 @@
+package goanda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeTransaction(t *testing.T) {
+	defer logTestResult(t, "DecodeTransaction")
+
+	tests := []struct {
+		data  string
+		check func(TypedTransaction) bool
+	}{
+		{`{"id":"1","type":"CREATE","homeCurrency":"USD"}`, func(tx TypedTransaction) bool {
+			c, ok := tx.(*CreateTransaction)
+			return ok && c.HomeCurrency == "USD"
+		}},
+		{`{"id":"2","type":"TRANSFER_FUNDS","amount":"1000.00","accountBalance":"1000.00"}`, func(tx TypedTransaction) bool {
+			f, ok := tx.(*TransferFundsTransaction)
+			return ok && f.Amount == "1000.00"
+		}},
+		{`{"id":"3","type":"LIMIT_ORDER","instrument":"EUR_USD","units":"100","price":"1.10000","timeInForce":"GTC"}`, func(tx TypedTransaction) bool {
+			l, ok := tx.(*LimitOrderTransaction)
+			return ok && l.Price == "1.10000" && l.TimeInForce == TimeInForceGTC
+		}},
+		{`{"id":"4","type":"STOP_ORDER_REJECT","instrument":"EUR_USD","priceBound":"1.2","rejectReason":"PRICE_PRECISION_EXCEEDED"}`, func(tx TypedTransaction) bool {
+			r, ok := tx.(*StopOrderRejectTransaction)
+			return ok && r.PriceBound == "1.2" && r.RejectReason == RejectPricePrecisionExceeded
+		}},
+		{`{"id":"5","type":"ORDER_FILL","orderID":"3","tradeOpened":{"tradeID":"5","units":"100"}}`, func(tx TypedTransaction) bool {
+			f, ok := tx.(*OrderFillTransaction)
+			return ok && f.TradeOpened != nil && f.TradeOpened.TradeID == "5"
+		}},
+		{`{"id":"6","type":"TRAILING_STOP_LOSS_ORDER","tradeID":"5","distance":"0.0050"}`, func(tx TypedTransaction) bool {
+			o, ok := tx.(*TrailingStopLossOrderTransaction)
+			return ok && o.TradeID == "5" && o.Distance == "0.0050"
+		}},
+		{`{"id":"7","type":"MARGIN_CALL_EXTEND","extensionNumber":2}`, func(tx TypedTransaction) bool {
+			m, ok := tx.(*MarginCallExtendTransaction)
+			return ok && m.ExtensionNumber == 2
+		}},
+		{`{"id":"8","type":"DAILY_FINANCING","financing":"-0.12","positionFinancings":[{"instrument":"EUR_USD","financing":"-0.12"}]}`, func(tx TypedTransaction) bool {
+			d, ok := tx.(*DailyFinancingTransaction)
+			return ok && len(d.PositionFinancings) == 1 && d.PositionFinancings[0].Financing == "-0.12"
+		}},
+		{`{"id":"9","type":"DIVIDEND_ADJUSTMENT","instrument":"SPX500_USD","dividendAdjustment":"1.5"}`, func(tx TypedTransaction) bool {
+			d, ok := tx.(*DividendAdjustmentTransaction)
+			return ok && d.DividendAdjustment == "1.5"
+		}},
+		{`{"id":"10","type":"RESET_RESETTABLE_PL"}`, func(tx TypedTransaction) bool {
+			_, ok := tx.(*ResetResettablePLTransaction)
+			return ok
+		}},
+		{`{"id":"11","type":"SOMETHING_NEW","extra":true}`, func(tx TypedTransaction) bool {
+			u, ok := tx.(*UnknownTransaction)
+			return ok && u.Type == "SOMETHING_NEW" && string(u.Raw) == `{"id":"11","type":"SOMETHING_NEW","extra":true}`
+		}},
+	}
+
+	for _, tt := range tests {
+		tx, err := DecodeTransaction([]byte(tt.data))
+		if err != nil {
+			t.Errorf("DecodeTransaction(%s) unexpected error: %v", tt.data, err)
+			continue
+		}
+		if !tt.check(tx) {
+			t.Errorf("DecodeTransaction(%s) = %#v", tt.data, tx)
+		}
+	}
+
+	if _, err := DecodeTransaction([]byte(`{"id":"12","type":"ORDER_FILL","units":"many"}`)); err == nil {
+		t.Error("Expected an error decoding a malformed transaction")
+	}
+}
+
+func TestAnyTransactionRoundTrip(t *testing.T) {
+	defer logTestResult(t, "AnyTransactionRoundTrip")
+
+	var v struct {
+		Transactions []AnyTransaction `json:"transactions"`
+		Missing      AnyTransaction   `json:"missing"`
+	}
+	data := `{"transactions":[{"id":"1","type":"ORDER_CANCEL","orderID":"2","reason":"CLIENT_REQUEST"},{"id":"3","type":"NEW_TYPE"}],"missing":null}`
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if v.Missing.TypedTransaction != nil {
+		t.Errorf("Expected a null transaction to be nil, got %#v", v.Missing.TypedTransaction)
+	}
+	if c, ok := v.Transactions[0].TypedTransaction.(*OrderCancelTransaction); !ok || c.OrderID != "2" {
+		t.Errorf("Unexpected order cancel: %#v", v.Transactions[0].TypedTransaction)
+	}
+
+	b, err := json.Marshal(v.Transactions)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var again []AnyTransaction
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again[1].Base().Type != "NEW_TYPE" {
+		t.Errorf("Expected the unknown transaction to survive a round trip, got %s", b)
+	}
+}
@@ -0,0 +1,322 @@
+package goanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// UnitsError is returned when an order's units are refused locally, before the order is sent
+//
+// Reason is the reject reason the api would have given for the order.
+type UnitsError struct {
+	Instrument string
+	Units      Decimal
+	Reason     RejectReason
+	Message    string
+}
+
+// UnitsError implements error
+func (e *UnitsError) Error() string {
+	return fmt.Sprintf("goanda: invalid units %q for %s: %s", e.Units.String(), e.Instrument, e.Message)
+}
+
+// ValidateUnits checks the number of units of an order for the instrument, which are
+// negative for a sell. It returns a *UnitsError if the units have more decimal places
+// than TradeUnitsPrecision, or are below MinimumTradeSize or above MaximumOrderUnits.
+func (i Instrument) ValidateUnits(units Decimal) error {
+	invalid := func(reason RejectReason, format string, args ...interface{}) error {
+		return &UnitsError{
+			Instrument: i.Name,
+			Units:      units,
+			Reason:     reason,
+			Message:    fmt.Sprintf(format, args...),
+		}
+	}
+
+	if units == "" {
+		return invalid(RejectUnitsMissing, "units must be given")
+	}
+	if !units.Valid() {
+		return invalid(RejectUnitsInvalid, "units are not a decimal number")
+	}
+	if units.IsZero() {
+		return invalid(RejectUnitsInvalid, "units must not be zero")
+	}
+
+	abs := units.Abs()
+	if !abs.Round(i.TradeUnitsPrecision).Equal(abs) {
+		return invalid(RejectUnitsPrecisionExceeded, "at most %d decimal places are allowed", i.TradeUnitsPrecision)
+	}
+	if i.MinimumTradeSize != "" && abs.Cmp(i.MinimumTradeSize) < 0 {
+		return invalid(RejectUnitsMinimumNotMet, "the minimum trade size is %s", i.MinimumTradeSize.String())
+	}
+	if !i.MaximumOrderUnits.IsZero() && abs.Cmp(i.MaximumOrderUnits) > 0 {
+		return invalid(RejectUnitsLimitExceeded, "the maximum order size is %s", i.MaximumOrderUnits.String())
+	}
+	return nil
+}
+
+func (c *Connection) cacheInstruments(instruments Instruments) {
+	cache := make(map[string]Instrument, len(instruments))
+	for _, i := range instruments {
+		cache[i.Name] = i
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.instruments = cache
+}
+
+// lookupInstrument returns the metadata of one of the account's instruments, fetching
+// the account's instruments if they haven't been cached yet
+func (c *Connection) lookupInstrument(ctx context.Context, name string) (Instrument, error) {
+	c.mu.RLock()
+	cache := c.instruments
+	c.mu.RUnlock()
+
+	if cache == nil {
+		if _, err := c.GetAccountInstrumentsContext(ctx, c.accountID); err != nil {
+			return Instrument{}, err
+		}
+
+		c.mu.RLock()
+		cache = c.instruments
+		c.mu.RUnlock()
+	}
+
+	instrument, ok := cache[name]
+	if !ok {
+		return Instrument{}, fmt.Errorf("goanda: instrument %q is not tradeable on account %s", name, c.accountID)
+	}
+	return instrument, nil
+}
+
+// validateOrderUnits checks the units of an order when the connection is configured to
+// validate orders. Orders without units, such as those closing a trade, are left to the api.
+func (c *Connection) validateOrderUnits(ctx context.Context, order OrderBody) error {
+	if !c.validateOrders || order.Units == "" {
+		return nil
+	}
+
+	instrument, err := c.lookupInstrument(ctx, order.Instrument)
+	if err != nil {
+		return err
+	}
+	return instrument.ValidateUnits(order.Units)
+}
+
+// Violation is a single reason an order would be rejected by the api
+type Violation struct {
+	Field   string
+	Reason  RejectReason
+	Message string
+}
+
+// ValidationError is returned by ValidateOrder with every violation found in the order
+type ValidationError struct {
+	Instrument string
+	Violations []Violation
+}
+
+// ValidationError implements error
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("goanda: order for %s would be rejected:", e.Instrument)
+	for i, v := range e.Violations {
+		if i > 0 {
+			msg += ";"
+		}
+		msg += fmt.Sprintf(" %s %s (%s)", v.Field, v.Message, v.Reason)
+	}
+	return msg
+}
+
+// ValidateOrder checks an order against the account's instrument metadata and margin
+// without placing it, so mistakes don't cost an ORDER_REJECT transaction. It returns
+// an *OrderError if a field the order's type requires is missing, a *ValidationError
+// listing every price precision, trailing stop distance, position size and margin
+// violation found, or the error of a request it needed to make.
+//
+// The instruments are fetched on first use and cached. Orders dependent on a trade look
+// up the trade to find its instrument, and orders opening or adding to a position fetch
+// the account's open positions and the margin available for the order.
+func (c *Connection) ValidateOrder(body OrderPayload) error {
+	return c.ValidateOrderContext(context.Background(), body)
+}
+
+// ValidateOrderContext is ValidateOrder bound to the given context
+func (c *Connection) ValidateOrderContext(ctx context.Context, body OrderPayload) error {
+	order := body.Order
+	if err := order.Validate(); err != nil {
+		return err
+	}
+
+	name := order.Instrument
+	if name == "" {
+		trade, err := c.GetTradeContext(ctx, order.TradeID)
+		if err != nil {
+			return err
+		}
+		name = trade.Trade.Instrument
+	}
+
+	instrument, err := c.lookupInstrument(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	violations := instrument.priceViolations(order)
+
+	if order.Units != "" {
+		if err := instrument.ValidateUnits(order.Units); err != nil {
+			ue := err.(*UnitsError)
+			violations = append(violations, Violation{Field: "units", Reason: ue.Reason, Message: ue.Message})
+		} else {
+			v, err := c.sizeViolations(ctx, instrument, order)
+			if err != nil {
+				return err
+			}
+			violations = append(violations, v...)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Instrument: name, Violations: violations}
+	}
+	return nil
+}
+
+// priceViolations checks the precision of the order's prices and the range of its
+// trailing stop distances
+func (i Instrument) priceViolations(order OrderBody) []Violation {
+	var violations []Violation
+
+	precision := func(field string, price Decimal, reason RejectReason) {
+		if price == "" {
+			return
+		}
+		if !price.Valid() {
+			violations = append(violations, Violation{field, RejectPriceInvalid, "is not a decimal number"})
+			return
+		}
+		if !price.Round(i.DisplayPrecision).Equal(price) {
+			violations = append(violations, Violation{
+				field, reason, fmt.Sprintf("has more than %d decimal places", i.DisplayPrecision),
+			})
+		}
+	}
+
+	trailing := func(field string, distance Decimal, minimum, maximum RejectReason) {
+		if distance == "" || !distance.Valid() {
+			return
+		}
+		if i.MinimumTrailingStopDistance != "" && distance.Cmp(i.MinimumTrailingStopDistance) < 0 {
+			violations = append(violations, Violation{
+				field, minimum, fmt.Sprintf("is below the minimum of %s (%s pips)",
+					i.MinimumTrailingStopDistance.String(), i.pips(i.MinimumTrailingStopDistance).String()),
+			})
+		}
+		if !i.MaximumTrailingStopDistance.IsZero() && distance.Cmp(i.MaximumTrailingStopDistance) > 0 {
+			violations = append(violations, Violation{
+				field, maximum, fmt.Sprintf("is above the maximum of %s (%s pips)",
+					i.MaximumTrailingStopDistance.String(), i.pips(i.MaximumTrailingStopDistance).String()),
+			})
+		}
+	}
+
+	precision("price", order.Price, RejectPricePrecisionExceeded)
+	precision("priceBound", order.PriceBound, RejectPriceBoundPrecisionExceeded)
+	precision("distance", order.Distance, RejectPriceDistancePrecisionExceeded)
+	if order.Type == OrderTypeTrailingStopLoss {
+		trailing("distance", order.Distance, RejectPriceDistanceMinimumNotMet, RejectPriceDistanceMaximumExceeded)
+	}
+
+	for field, onFill := range map[string]*OnFill{
+		"takeProfitOnFill":         order.TakeProfitOnFill,
+		"stopLossOnFill":           order.StopLossOnFill,
+		"guaranteedStopLossOnFill": order.GuaranteedStopLossOnFill,
+		"trailingStopLossOnFill":   order.TrailingStopLossOnFill,
+	} {
+		if onFill == nil {
+			continue
+		}
+		precision(field+".price", onFill.Price, RejectPricePrecisionExceeded)
+		precision(field+".distance", onFill.Distance, RejectPriceDistancePrecisionExceeded)
+	}
+	if order.TrailingStopLossOnFill != nil {
+		trailing("trailingStopLossOnFill.distance", order.TrailingStopLossOnFill.Distance,
+			RejectTrailingStopLossOnFillDistanceMinimum, RejectTrailingStopLossOnFillDistanceMaximum)
+	}
+
+	sortViolations(violations)
+	return violations
+}
+
+// sizeViolations checks that the position resulting from the order stays within the
+// instrument's maximum position size, and that the account has the margin to fill it
+func (c *Connection) sizeViolations(ctx context.Context, i Instrument, order OrderBody) ([]Violation, error) {
+	var violations []Violation
+
+	if !i.MaximumPositionSize.IsZero() && order.PositionFill != PositionFillReduceOnly {
+		positions, err := c.GetOpenPositionsContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		size := order.Units
+		for _, p := range positions.Positions {
+			if p.Instrument == i.Name {
+				size = size.Add(p.Long.Units).Add(p.Short.Units)
+			}
+		}
+		if size.Abs().Cmp(i.MaximumPositionSize) > 0 {
+			violations = append(violations, Violation{
+				"units", RejectUnitsLimitExceeded,
+				fmt.Sprintf("would make a position of %s, above the maximum of %s", size.String(), i.MaximumPositionSize.String()),
+			})
+		}
+	}
+
+	details, err := c.GetOrderDetailsContext(ctx, i.Name, order.Units)
+	if err != nil {
+		return nil, err
+	}
+
+	available := details.UnitsAvailable.Default
+	switch order.PositionFill {
+	case PositionFillOpenOnly:
+		available = details.UnitsAvailable.OpenOnly
+	case PositionFillReduceFirst:
+		available = details.UnitsAvailable.ReduceFirst
+	case PositionFillReduceOnly:
+		available = details.UnitsAvailable.ReduceOnly
+	}
+
+	side := available.Long
+	if order.Units.Sign() < 0 {
+		side = available.Short
+	}
+	if side != "" && order.Units.Abs().Cmp(side.Abs()) > 0 {
+		violations = append(violations, Violation{
+			"units", RejectInsufficientMargin,
+			fmt.Sprintf("exceed the %s units the account's margin allows", side.Abs().String()),
+		})
+	}
+
+	return violations, nil
+}
+
+// pips converts a price distance to pips
+func (i Instrument) pips(distance Decimal) Decimal {
+	pip, err := ParseDecimal(fmt.Sprintf("1e%d", i.PipLocation))
+	if err != nil {
+		return ""
+	}
+	return distance.Quo(pip, 1)
+}
+
+func sortViolations(violations []Violation) {
+	sort.SliceStable(violations, func(a, b int) bool {
+		return violations[a].Field < violations[b].Field
+	})
+}
@@ -0,0 +1,194 @@
+package goanda
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestInstrumentValidateUnits(t *testing.T) {
+	defer logTestResult(t, "InstrumentValidateUnits")
+
+	instrument := Instrument{
+		Name:                "BTC_USD",
+		TradeUnitsPrecision: 2,
+		MinimumTradeSize:    "0.05",
+		MaximumOrderUnits:   "100",
+	}
+
+	tests := []struct {
+		units  Decimal
+		reason RejectReason
+	}{
+		{"0.05", ""},
+		{"-0.05", ""},
+		{"12.50", ""},
+		{"-100", ""},
+		{"", RejectUnitsMissing},
+		{"ten", RejectUnitsInvalid},
+		{"0", RejectUnitsInvalid},
+		{"0.125", RejectUnitsPrecisionExceeded},
+		{"-1.001", RejectUnitsPrecisionExceeded},
+		{"0.01", RejectUnitsMinimumNotMet},
+		{"-0.04", RejectUnitsMinimumNotMet},
+		{"100.01", RejectUnitsLimitExceeded},
+		{"-250", RejectUnitsLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		err := instrument.ValidateUnits(tt.units)
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("ValidateUnits(%q) unexpected error: %v", tt.units, err)
+			}
+			continue
+		}
+
+		unitsErr, ok := err.(*UnitsError)
+		if !ok {
+			t.Errorf("ValidateUnits(%q) expected *UnitsError, got %v", tt.units, err)
+			continue
+		}
+		if unitsErr.Reason != tt.reason {
+			t.Errorf("ValidateUnits(%q) reason = %s, want %s", tt.units, unitsErr.Reason, tt.reason)
+		}
+	}
+}
+
+func TestCreateOrderValidatesUnits(t *testing.T) {
+	defer logTestResult(t, "CreateOrderValidatesUnits")
+
+	var instrumentCalls, orderCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/test-account/instruments":
+			atomic.AddInt32(&instrumentCalls, 1)
+			json.NewEncoder(w).Encode(map[string]Instruments{
+				"instruments": {{Name: "EUR_USD", TradeUnitsPrecision: 0, MinimumTradeSize: "1", MaximumOrderUnits: "100000000"}},
+			})
+		case "/accounts/test-account/orders":
+			atomic.AddInt32(&orderCalls, 1)
+			json.NewEncoder(w).Encode(OrderResponse{LastTransactionID: "1000"})
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:       server.URL,
+		accountID:      "test-account",
+		client:         *server.Client(),
+		validateOrders: true,
+	}
+
+	_, err := c.CreateOrder(OrderPayload{Order: OrderBody{Instrument: "EUR_USD", Units: "-0.5", Type: "MARKET"}})
+	if unitsErr, ok := err.(*UnitsError); !ok || unitsErr.Reason != RejectUnitsPrecisionExceeded {
+		t.Fatalf("Expected a precision *UnitsError, got %v", err)
+	}
+
+	_, err = c.CreateOrder(OrderPayload{Order: OrderBody{Instrument: "EUR_USD", Units: "-100", Type: "MARKET"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = c.CreateOrder(OrderPayload{Order: OrderBody{Instrument: "XAU_GBP", Units: "1", Type: "MARKET"}})
+	if err == nil {
+		t.Error("Expected an error for an instrument the account can't trade")
+	}
+
+	if instrumentCalls != 1 {
+		t.Errorf("Expected instruments to be fetched once, got %d", instrumentCalls)
+	}
+	if orderCalls != 1 {
+		t.Errorf("Expected 1 order to be sent, got %d", orderCalls)
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	defer logTestResult(t, "ValidateOrder")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/test-account/instruments":
+			json.NewEncoder(w).Encode(map[string]Instruments{
+				"instruments": {{
+					Name:                        "EUR_USD",
+					DisplayPrecision:            5,
+					PipLocation:                 -4,
+					MinimumTradeSize:            "1",
+					MaximumOrderUnits:           "100000000",
+					MaximumPositionSize:         "1000",
+					MinimumTrailingStopDistance: "0.00050",
+					MaximumTrailingStopDistance: "1.00000",
+				}},
+			})
+		case "/accounts/test-account/trades/42":
+			w.Write([]byte(`{"trade":{"id":"42","instrument":"EUR_USD"}}`))
+		case "/accounts/test-account/openPositions":
+			w.Write([]byte(`{"positions":[{"instrument":"EUR_USD","long":{"units":"600"},"short":{"units":"0"}}]}`))
+		case "/accounts/test-account/orderEntryData":
+			w.Write([]byte(`{"unitsAvailable":{"default":{"long":"500","short":"2000"}}}`))
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	c := &Connection{
+		hostname:  server.URL,
+		accountID: "test-account",
+		client:    *server.Client(),
+	}
+
+	violations := func(order *OrderBuilder) []Violation {
+		payload, err := order.Build()
+		if err != nil {
+			t.Fatalf("Unexpected error building order: %v", err)
+		}
+		err = c.ValidateOrder(payload)
+		if err == nil {
+			return nil
+		}
+		validationErr, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatalf("Expected *ValidationError, got %v", err)
+		}
+		return validationErr.Violations
+	}
+
+	if v := violations(NewLimitOrder("EUR_USD", "-100", "1.10000")); v != nil {
+		t.Errorf("Expected no violations, got %+v", v)
+	}
+
+	v := violations(NewLimitOrder("EUR_USD", "100", "1.100001").
+		WithTrailingStopLossOnFill(OnFill{Distance: "0.0001"}))
+	if len(v) != 2 {
+		t.Fatalf("Expected 2 violations, got %+v", v)
+	}
+	if v[0].Field != "price" || v[0].Reason != RejectPricePrecisionExceeded {
+		t.Errorf("Unexpected violation: %+v", v[0])
+	}
+	if v[1].Field != "trailingStopLossOnFill.distance" || v[1].Reason != RejectTrailingStopLossOnFillDistanceMinimum {
+		t.Errorf("Unexpected violation: %+v", v[1])
+	}
+
+	v = violations(NewTrailingStopLossOrder("42", "2.0"))
+	if len(v) != 1 || v[0].Reason != RejectPriceDistanceMaximumExceeded {
+		t.Errorf("Expected the trailing distance to be too large, got %+v", v)
+	}
+
+	v = violations(NewMarketOrder("EUR_USD", "450"))
+	if len(v) != 1 || v[0].Reason != RejectUnitsLimitExceeded {
+		t.Errorf("Expected the position to be too large, got %+v", v)
+	}
+
+	v = violations(NewMarketOrder("EUR_USD", "-2500"))
+	if len(v) != 2 || v[1].Reason != RejectInsufficientMargin {
+		t.Errorf("Expected insufficient margin, got %+v", v)
+	}
+}